@@ -17,13 +17,24 @@ var rootCmd = &cobra.Command{
 		host, _ := cmd.Flags().GetString("host")
 		port, _ := cmd.Flags().GetInt("port")
 		boundingBox, _ := cmd.Flags().GetBool("bounding-box")
+		lineNumbers, _ := cmd.Flags().GetBool("line-numbers")
+		lineNumbersStart, _ := cmd.Flags().GetInt("line-numbers-start")
 
 		var file string
 		if len(args) == 1 {
 			file = args[0]
 		}
 
-		parser := pkg.NewParser(theme)
+		parser, err := pkg.NewParserWithDefaults(
+			pkg.WithTheme(theme),
+			pkg.WithLineNumbers(lineNumbers),
+			pkg.WithLineNumbersStart(lineNumbersStart),
+			// Local documents are trusted and READMEs often contain HTML
+			pkg.WithRawHTMLEnabled(true),
+		)
+		if err != nil {
+			return err
+		}
 		server := pkg.NewServer(host, port, theme, boundingBox, browser, parser)
 		return server.Serve(file)
 	},
@@ -42,4 +53,6 @@ func init() {
 	rootCmd.Flags().StringP("host", "H", "localhost", "Host to use")
 	rootCmd.Flags().IntP("port", "p", 6419, "Port to use")
 	rootCmd.Flags().Bool("bounding-box", true, "Add bounding box to HTML")
+	rootCmd.Flags().Bool("line-numbers", false, "Add line numbers to code blocks")
+	rootCmd.Flags().Int("line-numbers-start", 1, "First line number of code blocks")
 }
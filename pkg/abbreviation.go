@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Matches a definition like "*[HTTP]: Hypertext Transfer Protocol"
+var abbreviationRegex = regexp.MustCompile(`^\*\[(\w+)\]:\s+(.+)$`)
+
+// abbreviation is an occurrence of an abbreviation in the text, rendered as
+// <abbr> element
+type abbreviation struct {
+	ast.Leaf
+	Title string
+}
+
+// expandAbbreviations removes the definitions of abbreviations from the
+// document and replaces the abbreviations in the text with abbreviation nodes
+func expandAbbreviations(doc ast.Node) {
+	titles := map[string]string{}
+	var texts []*ast.Text
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		if paragraph, ok := node.(*ast.Paragraph); ok {
+			cutAbbreviations(paragraph, titles)
+		} else if text, ok := node.(*ast.Text); ok {
+			texts = append(texts, text)
+		}
+		return ast.GoToNext
+	})
+	if len(titles) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(titles))
+	for name := range titles {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	regex := regexp.MustCompile(`\b(?:` + strings.Join(names, "|") + `)\b`)
+
+	for _, text := range texts {
+		// Texts of removed definitions
+		if text.GetParent().GetParent() == nil {
+			continue
+		}
+		splitAbbreviations(text, regex, titles)
+	}
+}
+
+// cutAbbreviations removes the lines defining abbreviations from the end of
+// a paragraph, and the paragraph if nothing is left
+func cutAbbreviations(paragraph *ast.Paragraph, titles map[string]string) {
+	children := paragraph.GetChildren()
+	text, ok := ast.GetLastChild(paragraph).(*ast.Text)
+	if !ok {
+		return
+	}
+
+	lines := strings.Split(string(text.Literal), "\n")
+	end := len(lines)
+	// The first line of the text only starts a line if nothing precedes it
+	for end > 0 && (end > 1 || len(children) == 1) {
+		match := abbreviationRegex.FindStringSubmatch(lines[end-1])
+		if match == nil {
+			break
+		}
+		titles[match[1]] = strings.TrimSpace(match[2])
+		end--
+	}
+	if end == 0 {
+		ast.RemoveFromTree(paragraph)
+		return
+	}
+	text.Literal = []byte(strings.Join(lines[:end], "\n"))
+}
+
+// splitAbbreviations replaces a text with the texts between the abbreviations
+// and the abbreviations
+func splitAbbreviations(text *ast.Text, regex *regexp.Regexp, titles map[string]string) {
+	literal := string(text.Literal)
+	matches := regex.FindAllStringIndex(literal, -1)
+	if matches == nil {
+		return
+	}
+
+	var nodes []ast.Node
+	last := 0
+	for _, match := range matches {
+		if match[0] > last {
+			nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: []byte(literal[last:match[0]])}})
+		}
+		name := literal[match[0]:match[1]]
+		nodes = append(nodes, &abbreviation{Leaf: ast.Leaf{Literal: []byte(name)}, Title: titles[name]})
+		last = match[1]
+	}
+	if last < len(literal) {
+		nodes = append(nodes, &ast.Text{Leaf: ast.Leaf{Literal: []byte(literal[last:])}})
+	}
+
+	parent := text.GetParent()
+	var children []ast.Node
+	for _, child := range parent.GetChildren() {
+		if child != text {
+			children = append(children, child)
+			continue
+		}
+		for _, node := range nodes {
+			node.SetParent(parent)
+			children = append(children, node)
+		}
+	}
+	parent.SetChildren(children)
+}
+
+func renderHookAbbreviation(w io.Writer, node *abbreviation) (ast.WalkStatus, bool, error) {
+	_, err := io.WriteString(w, `<abbr title="`+template.HTMLEscapeString(node.Title)+`">`+
+		template.HTMLEscapeString(string(node.Literal))+"</abbr>")
+	return ast.GoToNext, true, err
+}
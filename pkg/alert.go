@@ -0,0 +1,258 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+var blockquotes = []string{"Note", "Tip", "Important", "Warning", "Caution", "BlockQuote", "Quote"}
+
+// Matches [!NOTE] and the collapsible variant [!DETAILS NOTE]
+var alertRegex = regexp.MustCompile(`^\[!(DETAILS )?([A-Z][A-Z0-9_-]*)\]`)
+
+// Matches lines of blockquotes
+var quoteLineRegex = regexp.MustCompile(`^ {0,3}>`)
+
+// blockquoteSeparator ends a blockquote in the markdown and is dropped by the
+// renderer
+const blockquoteSeparator = "<!-- go-grip:end-blockquote -->"
+
+type alert struct {
+	name        string
+	marker      string
+	title       string
+	collapsible bool
+	// Fenced div without alert type, name is its class
+	div bool
+	// Collapsible paragraph, title is its summary
+	summary bool
+}
+
+type alertData struct {
+	Type  string
+	Title string
+	Icon  template.HTML
+	Role  string
+}
+
+// findAlert reports the alert of a blockquote starting with an alert marker
+func (m Parser) findAlert(node ast.Node) (alert, bool) {
+	if _, ok := node.(*ast.BlockQuote); !ok || len(node.GetChildren()) == 0 {
+		return alert{}, false
+	}
+
+	paragraph, ok := node.GetChildren()[0].(*ast.Paragraph)
+	if !ok || len(paragraph.GetChildren()) == 0 {
+		return alert{}, false
+	}
+
+	t, ok := paragraph.GetChildren()[0].(*ast.Text)
+	if !ok {
+		return alert{}, false
+	}
+
+	if match := fencedDivMarkerRegex.FindStringSubmatch(string(t.Literal)); match != nil && m.opts.FencedDivs {
+		return alert{name: match[1], marker: match[0], div: true}, true
+	}
+	if match := collapsibleMarkerRegex.FindString(string(t.Literal)); match != "" && m.opts.CollapsibleParagraphs {
+		summary, _, _ := strings.Cut(strings.TrimPrefix(string(t.Literal), match), "\n")
+		return alert{name: "summary", marker: match, title: strings.TrimSpace(summary), summary: true}, true
+	}
+
+	match := alertRegex.FindStringSubmatch(string(t.Literal))
+	if match == nil {
+		return alert{}, false
+	}
+
+	// Text following the marker on the same line overrides the title
+	firstLine, _, _ := strings.Cut(strings.TrimPrefix(string(t.Literal), match[0]), "\n")
+
+	for _, b := range append(blockquotes, m.opts.ExtraAlertTypes...) {
+		if match[2] == strings.ToUpper(b) {
+			return alert{
+				name:        b,
+				marker:      match[0],
+				title:       strings.TrimSpace(firstLine),
+				collapsible: match[1] != "",
+			}, true
+		}
+	}
+	return alert{}, false
+}
+
+// separateBlockquotes ends blockquotes followed by a blank line and another
+// blockquote, which the markdown parser would merge, with a separator
+func separateBlockquotes(input []byte) []byte {
+	lines := strings.Split(string(input), "\n")
+	var fence string
+	quote := false
+	for i, line := range lines {
+		if match := codeFenceRegex.FindStringSubmatch(line); match != nil {
+			if fence == "" {
+				fence = match[1]
+			} else if match[1][0] == fence[0] && len(match[1]) >= len(fence) {
+				fence = ""
+			}
+			quote = false
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		switch {
+		case quoteLineRegex.MatchString(line):
+			quote = true
+		case strings.TrimSpace(line) != "":
+			// Lazy continuation lines belong to the blockquote
+			quote = quote && i > 0 && strings.TrimSpace(lines[i-1]) != ""
+		case quote:
+			quote = false
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next < len(lines) && quoteLineRegex.MatchString(lines[next]) {
+				lines[i] = "\n" + blockquoteSeparator + "\n"
+			}
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// isBlockquoteSeparator reports whether the node is a separator inserted by
+// separateBlockquotes
+func isBlockquoteSeparator(node ast.Node) bool {
+	block, ok := node.(*ast.HTMLBlock)
+	return ok && strings.TrimSpace(string(block.Literal)) == blockquoteSeparator
+}
+
+// cutAlertMarker removes the alert marker line from the first text of an alert
+func (m Parser) cutAlertMarker(t *ast.Text) (string, bool) {
+	paragraph := t.GetParent()
+	if paragraph == nil || ast.GetFirstChild(paragraph) != t {
+		return "", false
+	}
+
+	quote := paragraph.GetParent()
+	if quote == nil || ast.GetFirstChild(quote) != paragraph {
+		return "", false
+	}
+
+	a, ok := m.findAlert(quote)
+	if !ok {
+		return "", false
+	}
+
+	// Drop the marker together with the title on the same line
+	_, content, _ := strings.Cut(strings.TrimPrefix(string(t.Literal), a.marker), "\n")
+	return strings.TrimLeft(content, " \t\n"), true
+}
+
+// quoteAttribution returns the paragraph of a [!QUOTE] alert naming the
+// author, e.g. "— Author Name"
+func (m Parser) quoteAttribution(node ast.Node) *ast.Paragraph {
+	a, ok := m.findAlert(node)
+	if !ok || a.name != "Quote" {
+		return nil
+	}
+
+	for _, child := range node.GetChildren()[1:] {
+		paragraph, ok := child.(*ast.Paragraph)
+		if !ok || len(paragraph.GetChildren()) != 1 {
+			continue
+		}
+		t, ok := paragraph.GetChildren()[0].(*ast.Text)
+		if ok && (strings.HasPrefix(string(t.Literal), "—") || strings.HasPrefix(string(t.Literal), "--")) {
+			return paragraph
+		}
+	}
+	return nil
+}
+
+func (m Parser) renderHookBlockQuote(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool, error) {
+	a, ok := m.findAlert(node)
+	if !ok {
+		return ast.GoToNext, false, nil
+	}
+
+	if !entering {
+		var end string
+		if attribution := m.quoteAttribution(node); attribution != nil {
+			t := attribution.GetChildren()[0].(*ast.Text)
+			author := strings.TrimSpace(strings.TrimLeft(string(t.Literal), "—-"))
+			end = fmt.Sprintf("<footer><cite>%s</cite></footer>", template.HTMLEscapeString(author))
+		}
+
+		switch {
+		case a.summary:
+			end += "</details>"
+		case a.collapsible:
+			end += "</div></details>"
+		case a.name == "Quote":
+			end += "</blockquote>"
+		default:
+			end += "</div>"
+		}
+		_, err := io.WriteString(w, end)
+		return ast.GoToNext, true, err
+	}
+
+	s, err := m.createBlockquoteStart(a)
+	if err != nil {
+		return ast.GoToNext, true, err
+	}
+	_, err = io.WriteString(w, s)
+	return ast.GoToNext, true, err
+}
+
+func (m Parser) createBlockquoteStart(a alert) (string, error) {
+	if a.div {
+		return `<div class="fenced-div ` + template.HTMLEscapeString(a.name) + `">`, nil
+	}
+	if a.summary {
+		return "<details>\n<summary>" + template.HTMLEscapeString(a.title) + "</summary>\n", nil
+	}
+
+	data := alertData{
+		Type:  strings.ToLower(a.name),
+		Title: a.name,
+		Role:  "alert",
+	}
+	// Only warnings and errors should be announced as live region
+	if data.Type == "note" || data.Type == "tip" || data.Type == "quote" {
+		data.Role = "note"
+	}
+	if a.title != "" {
+		data.Title = a.title
+	} else if title, ok := m.opts.AlertTitles[data.Type]; ok {
+		data.Title = title
+	}
+	// Without an icon the template uses its built-in one
+	data.Icon = m.opts.AlertIcons[data.Type]
+
+	lp := path.Join("templates/alert", fmt.Sprintf("%s.html", data.Type))
+	if a.collapsible {
+		lp = path.Join("templates/alert", "details.html")
+	} else if !m.templateExists(lp) {
+		// Alert types without an own template use a generic one
+		lp = path.Join("templates/alert", "custom.html")
+	}
+
+	tmpl, err := m.parseTemplate(lp)
+	if err != nil {
+		return "", err
+	}
+	var tpl bytes.Buffer
+	if err := tmpl.Execute(&tpl, data); err != nil {
+		return "", err
+	}
+	return tpl.String(), nil
+}
@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConsecutiveAlerts(t *testing.T) {
+	// The markdown parser merges blockquotes separated by a blank line
+	p, err := NewParserWithDefaults(WithRawHTMLEnabled(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("alerts", func(t *testing.T) {
+		out, err := p.MdToHTML([]byte("> [!NOTE]\n> a\n\n> [!WARNING]\n> b\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		html := string(out)
+		if strings.Contains(html, "[!WARNING]") {
+			t.Errorf("warning rendered as text of the note:\n%s", html)
+		}
+		note := strings.Index(html, "markdown-alert-note")
+		warning := strings.Index(html, "markdown-alert-warning")
+		if note < 0 || warning < 0 {
+			t.Fatalf("want a note and a warning alert:\n%s", html)
+		}
+		// The note is closed before the warning starts
+		if !strings.Contains(html[note:warning], "<p>a</p>\n</div>") {
+			t.Errorf("warning nested in the note:\n%s", html)
+		}
+		if !strings.Contains(html[warning:], "<p>b</p>") {
+			t.Errorf("body of the warning missing:\n%s", html)
+		}
+	})
+
+	t.Run("alert and quote", func(t *testing.T) {
+		out, err := p.MdToHTML([]byte("> [!WARNING]\n> a\n\n> plain quote\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		html := string(out)
+		end := strings.Index(html, "</div>")
+		quote := strings.Index(html, "<blockquote>\n<p>plain quote</p>\n</blockquote>")
+		if end < 0 || quote < end {
+			t.Errorf("quote nested in the warning:\n%s", html)
+		}
+		if strings.Contains(html, "<!--") {
+			t.Errorf("separator in the output:\n%s", html)
+		}
+	})
+
+	t.Run("one blockquote", func(t *testing.T) {
+		// Lines starting with ">" continue the blockquote like on GitHub
+		out, err := p.MdToHTML([]byte("> [!TIP]\n> a\n>\n> b\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if html := string(out); strings.Count(html, "markdown-alert-tip") != 1 || !strings.Contains(html, "<p>b</p>\n</div>") {
+			t.Errorf("want one alert:\n%s", html)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package pkg
+
+import "sync"
+
+// BatchRender renders the documents with the given number of goroutines. The
+// HTML and error of every document are at its index in the input.
+func (m Parser) BatchRender(inputs [][]byte, workers int) ([][]byte, []error) {
+	outputs := make([][]byte, len(inputs))
+	errs := make([]error, len(inputs))
+	if workers < 1 {
+		workers = 1
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				outputs[i], errs[i] = m.MdToHTML(inputs[i])
+			}
+		}()
+	}
+	for i := range inputs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return outputs, errs
+}
@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Results are kept in testdata/bench.txt, compare them with benchstat:
+//
+//	go test -run '^$' -bench . -count 6 ./pkg > new.txt
+//	benchstat pkg/testdata/bench.txt new.txt
+
+func benchmarkMdToHTML(b *testing.B, input []byte) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.MdToHTML(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func readBenchDoc(b *testing.B) []byte {
+	input, err := os.ReadFile("testdata/bench.md")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return input
+}
+
+func BenchmarkMdToHTML_Small(b *testing.B) {
+	benchmarkMdToHTML(b, []byte("# Hello\n\nSome *emphasis*, a [link](https://example.com) and `code` :smile:.\n"))
+}
+
+func BenchmarkMdToHTML_Medium(b *testing.B) {
+	benchmarkMdToHTML(b, readBenchDoc(b))
+}
+
+// A markdown parser is created per document, as they cannot be reused, the
+// allocations of a typical ~10 KB document show its cost
+func BenchmarkMdToHTML_10KB(b *testing.B) {
+	benchmarkMdToHTML(b, bytes.Repeat(readBenchDoc(b), 3))
+}
+
+func BenchmarkMdToHTML_Large(b *testing.B) {
+	benchmarkMdToHTML(b, bytes.Repeat(readBenchDoc(b), 20))
+}
+
+// Alerts and mermaid diagrams are rendered with templates, which are parsed
+// once per parser
+func BenchmarkMdToHTML_Templates(b *testing.B) {
+	var input strings.Builder
+	for i := 0; i < 20; i++ {
+		for _, alert := range []string{"NOTE", "TIP", "IMPORTANT", "WARNING", "CAUTION"} {
+			fmt.Fprintf(&input, "> [!%s]\n> Alert %d\n\n", alert, i)
+		}
+		fmt.Fprintf(&input, "```mermaid\ngraph LR\n    A%d --> B%d\n```\n\n", i, i)
+	}
+	benchmarkMdToHTML(b, []byte(input.String()))
+}
+
+func BenchmarkRenderHookCodeBlock_Highlight(b *testing.B) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		b.Fatal(err)
+	}
+	state := &renderState{ctx: context.Background(), parser: *p}
+	block := &ast.CodeBlock{Info: []byte("go")}
+	block.Literal = []byte(strings.Repeat("func add(a, b int) int {\n\treturn a + b // sum\n}\n\n", 25))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := state.renderHookCodeBlock(io.Discard, block); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// The emoji regex is compiled once instead of for every text
+func BenchmarkRenderHookText(b *testing.B) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		b.Fatal(err)
+	}
+	paragraph := &ast.Paragraph{}
+	text := &ast.Text{}
+	text.Literal = []byte("Ship it :rocket: once the tests pass, then celebrate :tada:")
+	ast.AppendChild(paragraph, text)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := p.renderHookText(io.Discard, text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderHookText_ManyEmoji(b *testing.B) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		b.Fatal(err)
+	}
+	paragraph := &ast.Paragraph{}
+	text := &ast.Text{}
+	text.Literal = []byte(strings.Repeat("Great work :tada: :rocket: :+1: on the :bug: fix :heart: ", 20))
+	ast.AppendChild(paragraph, text)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := p.renderHookText(io.Discard, text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderMermaid(b *testing.B) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		b.Fatal(err)
+	}
+	state := &renderState{ctx: context.Background(), parser: *p}
+	diagram := "graph LR\n    A[Merge] --> B[Build]\n    B --> C{Tests pass?}\n    C -->|yes| D[Deploy]\n    C -->|no| E[Notify]\n"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := state.renderMermaid(diagram); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMinifyHTML(b *testing.B) {
+	input, err := os.ReadFile("../README.md")
+	if err != nil {
+		b.Fatal(err)
+	}
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		b.Fatal(err)
+	}
+	html, err := p.MdToHTML(input)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var out []byte
+	b.ReportAllocs()
+	b.SetBytes(int64(len(html)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if out, err = minifyHTML(html); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(100*float64(len(html)-len(out))/float64(len(html)), "%smaller")
+}
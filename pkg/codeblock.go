@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+var fenceRegex = regexp.MustCompile("^(```|~~~)")
+
+type CodeBlock struct {
+	Language string
+	Content  string
+	// LineNumber is the line of the first content line in the input, starting
+	// at 1, or 0 if it could not be determined
+	LineNumber int
+}
+
+// ExtractCodeBlocks returns all code blocks of the document
+func (m Parser) ExtractCodeBlocks(input []byte) []CodeBlock {
+	lines := strings.Split(string(bytes.ReplaceAll(input, []byte("\r\n"), []byte("\n"))), "\n")
+	// Code blocks appear in source order, so the search continues after the
+	// previous one
+	cursor := 0
+
+	var blocks []CodeBlock
+	ast.WalkFunc(m.parse(input), func(node ast.Node, entering bool) ast.WalkStatus {
+		block, ok := node.(*ast.CodeBlock)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+
+		line := findCodeBlockLine(lines, cursor, block)
+		if line > 0 {
+			cursor = line - 1 + strings.Count(string(block.Literal), "\n")
+			if block.IsFenced {
+				// Skip the closing fence
+				cursor++
+			}
+		}
+
+		blocks = append(blocks, CodeBlock{
+			Language:   parseFenceInfo(block.Info).lang,
+			Content:    string(block.Literal),
+			LineNumber: line,
+		})
+		return ast.GoToNext
+	})
+	return blocks
+}
+
+// findCodeBlockLine returns the 1-based line of the first content line of the
+// block, searching from the cursor
+func findCodeBlockLine(lines []string, cursor int, block *ast.CodeBlock) int {
+	if block.IsFenced {
+		for i := cursor; i < len(lines); i++ {
+			// Fences may be nested inside of blockquotes and lists
+			if fenceRegex.MatchString(strings.TrimLeft(lines[i], " \t>")) {
+				return i + 2
+			}
+		}
+		return 0
+	}
+
+	first, _, _ := strings.Cut(string(block.Literal), "\n")
+	first = strings.TrimSpace(first)
+	for i := cursor; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == first {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// Matches the first line of a collapsible paragraph, ">>> summary"
+	collapsibleRegex = regexp.MustCompile(`^>>> (.*)$`)
+	// Marker of blockquotes converted from collapsible paragraphs, followed
+	// by the summary
+	collapsibleMarkerRegex = regexp.MustCompile(`^\[!SUMMARY\]`)
+)
+
+// expandCollapsibleParagraphs converts paragraphs starting with ">>> " into
+// blockquotes marked with [!SUMMARY], as the markdown parser would read them
+// as three nested blockquotes. A ">>> " line inside of such a paragraph nests
+// another one, the paragraphs end at the next blank line.
+func expandCollapsibleParagraphs(input []byte) []byte {
+	source := strings.Split(string(input), "\n")
+	lines := slices.Clone(source)
+	depth := 0
+	var fence string
+	for i, line := range lines {
+		if depth == 0 {
+			if match := codeFenceRegex.FindStringSubmatch(line); match != nil {
+				if fence == "" {
+					fence = match[1]
+				} else if match[1][0] == fence[0] && len(match[1]) >= len(fence) {
+					fence = ""
+				}
+				continue
+			}
+			if fence != "" {
+				continue
+			}
+		}
+
+		prefix := strings.Repeat("> ", depth)
+		match := collapsibleRegex.FindStringSubmatch(line)
+		switch {
+		case match != nil && (depth > 0 || i == 0 || strings.TrimSpace(source[i-1]) == ""):
+			lines[i] = prefix + "> [!SUMMARY] " + match[1]
+			depth++
+		case depth > 0 && strings.TrimSpace(line) == "":
+			// Blockquotes separated by blank lines are merged, a separator
+			// ends them
+			lines[i] = "\n" + blockquoteSeparator + "\n"
+			depth = 0
+		case depth > 0:
+			lines[i] = prefix + line
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
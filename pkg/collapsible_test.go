@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollapsibleParagraphs(t *testing.T) {
+	// Raw HTML is enabled, as in the CLI, so that separators would show up
+	p, err := NewParserWithDefaults(WithCollapsibleParagraphs(true), WithRawHTMLEnabled(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "single",
+			input: ">>> Summary\nbody\n",
+			want:  "<details><summary>Summary</summary><p>body</p></details>",
+		},
+		{
+			name:  "nested",
+			input: ">>> Outer\nouter\n>>> Inner\ninner\n",
+			want:  "<details><summary>Outer</summary><p>outer</p><details><summary>Inner</summary><p>inner</p></details></details>",
+		},
+		{
+			name:  "ends at blank line",
+			input: ">>> Summary\nbody\n\nafter\n",
+			want:  "<p>body</p></details><p>after</p>",
+		},
+		{
+			name:  "consecutive",
+			input: ">>> First\na\n\n>>> Second\nb\n",
+			want:  "<p>a</p></details><details><summary>Second</summary>",
+		},
+		{
+			name:  "in code block",
+			input: "```\n>>> Summary\n```\n",
+			want:  "&gt;&gt;&gt; Summary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.MdToHTML([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Newlines between the elements depend on the separators
+			if !strings.Contains(strings.ReplaceAll(string(out), "\n", ""), tt.want) {
+				t.Errorf("output lacks %q:\n%s", tt.want, out)
+			}
+			if strings.Contains(string(out), "<!--") {
+				t.Errorf("separator in the output:\n%s", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// stripComments removes the HTML comments from the output. With
+// preserveConditional, conditional comments like <!--[if IE]> are kept.
+func stripComments(input []byte, preserveConditional bool) []byte {
+	var out bytes.Buffer
+	tokenizer := html.NewTokenizer(bytes.NewReader(input))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return out.Bytes()
+		}
+		raw := tokenizer.Raw()
+		if tokenType == html.CommentToken && !(preserveConditional && isConditionalComment(tokenizer.Token().Data)) {
+			continue
+		}
+		out.Write(raw)
+	}
+}
+
+// isConditionalComment reports whether the comment is the start or end of a
+// conditional comment of Internet Explorer
+func isConditionalComment(data string) bool {
+	return strings.HasPrefix(data, "[if ") || strings.HasPrefix(data, "<![endif]") || strings.HasSuffix(data, "<![endif]")
+}
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewParserFromConfig creates a parser with the default options overridden
+// by a TOML (.toml), YAML (.yaml, .yml) or JSON (.json) file. The keys are
+// the names of the ParserOptions fields in any case, with optional "_" or "-"
+// between words, e.g. "line_numbers = true".
+func NewParserFromConfig(path string) (*Parser, error) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fence string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		fence = "+++"
+	case ".yaml", ".yml":
+		fence = "---"
+	case ".json":
+		fence = "{"
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", ext)
+	}
+	config, err := parseFrontMatter(fence, input)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	opts := DefaultParserOptions()
+	if err := decodeConfig(config, &opts); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewParser(opts)
+}
+
+// decodeConfig sets the options named by the keys of the config
+func decodeConfig(config map[string]any, opts *ParserOptions) error {
+	// JSON matches the keys case-insensitively with the field names
+	fields := map[string]any{}
+	for key, value := range config {
+		fields[strings.NewReplacer("_", "", "-", "").Replace(key)] = value
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, opts)
+}
+
+// jsonOptions has the fields of ParserOptions without its JSON methods
+type jsonOptions ParserOptions
+
+// optionsJSON replaces the fields of ParserOptions which cannot be
+// serialized. The empty raw messages are omitted and take any value, fields
+// tagged "-" would not hide the embedded ones.
+type optionsJSON struct {
+	jsonOptions
+	TemplateFS       json.RawMessage `json:",omitempty"`
+	ServeFS          json.RawMessage `json:",omitempty"`
+	DiagramRenderers json.RawMessage `json:",omitempty"`
+	BaseURL          string          `json:",omitempty"`
+}
+
+// MarshalJSON serializes the options without the file systems and diagram
+// renderers
+func (o ParserOptions) MarshalJSON() ([]byte, error) {
+	aux := optionsJSON{jsonOptions: jsonOptions(o)}
+	if o.BaseURL != nil {
+		aux.BaseURL = o.BaseURL.String()
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON sets the options contained in the JSON, the others keep their
+// values
+func (o *ParserOptions) UnmarshalJSON(data []byte) error {
+	aux := optionsJSON{jsonOptions: jsonOptions(*o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*o = ParserOptions(aux.jsonOptions)
+	if aux.BaseURL != "" {
+		base, err := url.Parse(aux.BaseURL)
+		if err != nil {
+			return err
+		}
+		o.BaseURL = base
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewParserFromConfig(t *testing.T) {
+	t.Run("toml", func(t *testing.T) {
+		p, err := NewParserFromConfig("testdata/config.toml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.opts.Theme != "dark" || !p.opts.LineNumbers || p.opts.LineNumbersStart != 10 {
+			t.Errorf("scalar options not set: %+v", p.opts)
+		}
+		if got := p.opts.ExtraEmoji[":company-logo:"]; got != "/assets/logo.svg" {
+			t.Errorf("ExtraEmoji = %v", p.opts.ExtraEmoji)
+		}
+		if got := p.opts.MermaidConfig["fontSize"]; got != float64(16) {
+			t.Errorf("MermaidConfig = %v", p.opts.MermaidConfig)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		p, err := NewParserFromConfig("testdata/config.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(p.opts.ExtraAlertTypes, []string{"Deprecated"}) {
+			t.Errorf("ExtraAlertTypes = %v", p.opts.ExtraAlertTypes)
+		}
+		want := map[string]string{"note": "Hinweis", "warning": "Achtung"}
+		if !reflect.DeepEqual(p.opts.AlertTitles, want) {
+			t.Errorf("AlertTitles = %v, want %v", p.opts.AlertTitles, want)
+		}
+		flowchart, _ := p.opts.MermaidConfig["flowchart"].(map[string]any)
+		if flowchart["curve"] != "basis" {
+			t.Errorf("MermaidConfig = %v", p.opts.MermaidConfig)
+		}
+	})
+}
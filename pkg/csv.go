@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"strings"
+	"unicode/utf8"
+)
+
+// renderCSV renders the content of a csv or tsv code block as table. The
+// fence info can set the delimiter, e.g. "delimiter=;" or "delimiter=tab",
+// disable the header row with "header=false" and add a class with "class=…".
+func renderCSV(info fenceInfo, content string) (string, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	if info.lang == "tsv" {
+		r.Comma = '\t'
+	}
+	if d, ok := info.attrs["delimiter"]; ok {
+		if d == "tab" {
+			d = "\t"
+		}
+		delimiter, size := utf8.DecodeRuneInString(d)
+		if size == 0 || size != len(d) {
+			return "", fmt.Errorf("invalid %s delimiter %q", info.lang, d)
+		}
+		r.Comma = delimiter
+	}
+
+	rows, err := r.ReadAll()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("<table")
+	if class, ok := info.attrs["class"]; ok {
+		fmt.Fprintf(&b, ` class="%s"`, template.HTMLEscapeString(class))
+	}
+	b.WriteString(">\n")
+
+	if info.attrs["header"] != "false" && len(rows) > 0 {
+		b.WriteString("<thead>\n")
+		writeCSVRow(&b, rows[0], "th")
+		b.WriteString("</thead>\n")
+		rows = rows[1:]
+	}
+	b.WriteString("<tbody>\n")
+	for _, row := range rows {
+		writeCSVRow(&b, row, "td")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+	return b.String(), nil
+}
+
+func writeCSVRow(b *strings.Builder, row []string, cell string) {
+	b.WriteString("<tr>")
+	for _, value := range row {
+		fmt.Fprintf(b, "<%s>%s</%s>", cell, template.HTMLEscapeString(value), cell)
+	}
+	b.WriteString("</tr>\n")
+}
@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"fmt"
+	"html/template"
+	"slices"
+)
+
+// DiagramRenderer renders the code blocks of diagram languages like PlantUML
+// or D2 to HTML
+type DiagramRenderer interface {
+	// Languages of the code blocks handled by the renderer, e.g. "plantuml"
+	Languages() []string
+	// Render returns the HTML of the diagram for the theme of the document
+	Render(content, theme string) (string, error)
+}
+
+// renderDiagram renders the code block with the first renderer handling its
+// language. If there is none, found is false.
+func (r *renderState) renderDiagram(lang, content string) (diagram string, found bool, err error) {
+	for _, renderer := range r.parser.opts.DiagramRenderers {
+		if slices.Contains(renderer.Languages(), lang) {
+			diagram, err = renderer.Render(content, r.parser.opts.Theme)
+			if err != nil {
+				diagram = sourceFallback(lang, content)
+			}
+			return diagram, true, err
+		}
+	}
+
+	if lang == "mermaid" {
+		diagram, err = r.renderMermaid(content)
+		if err != nil {
+			diagram = sourceFallback(lang, content)
+		}
+		return diagram, true, err
+	}
+	return "", false, nil
+}
+
+// sourceFallback keeps the source of a code block which failed to render,
+// e.g. a diagram, instead of dropping it
+func sourceFallback(lang, content string) string {
+	return fmt.Sprintf(`<pre data-render-error="%s"><code class="language-%s">%s</code></pre>`,
+		template.HTMLEscapeString(lang), template.HTMLEscapeString(lang), template.HTMLEscapeString(content))
+}
@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"html/template"
+	"strings"
+)
+
+// renderSemanticDiff renders a diff with additions in <ins> and deletions in
+// <del> elements instead of highlighting it with chroma
+func renderSemanticDiff(content string) string {
+	var b strings.Builder
+	b.WriteString(`<pre class="semantic-diff"><code>`)
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		text := template.HTMLEscapeString(strings.TrimSuffix(line, "\n"))
+
+		// The file names of unified diffs are no changes
+		isHeader := strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ")
+		switch {
+		case !isHeader && strings.HasPrefix(line, "+"):
+			b.WriteString("<ins>" + text + "</ins>")
+		case !isHeader && strings.HasPrefix(line, "-"):
+			b.WriteString("<del>" + text + "</del>")
+		default:
+			b.WriteString(text)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return b.String()
+}
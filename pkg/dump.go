@@ -0,0 +1,16 @@
+package pkg
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// DumpAST returns the syntax tree of the document as rendered by MdToHTML,
+// one indented node per line. The format is meant for debugging only and
+// may change.
+func (m Parser) DumpAST(input []byte) string {
+	var b strings.Builder
+	ast.Print(&b, m.parse(input))
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var lineRangesRegex = regexp.MustCompile(`^\{[\d,\-]+\}$`)
+
+type fenceInfo struct {
+	lang      string
+	attrs     map[string]string
+	highlight string
+}
+
+// parseFenceInfo splits the info string of a fenced code block into the
+// language and optional annotations, e.g. "go {1,3-5} {linestart=42}" or
+// `go title="cmd/main.go"`.
+func parseFenceInfo(info []byte) fenceInfo {
+	f := fenceInfo{attrs: map[string]string{}}
+
+	for i, token := range splitUnquoted(string(info), unicode.IsSpace) {
+		if lineRangesRegex.MatchString(token) {
+			f.highlight = strings.Trim(token, "{}")
+			continue
+		}
+
+		if strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}") {
+			token = strings.TrimSuffix(strings.TrimPrefix(token, "{"), "}")
+		} else if i == 0 && !strings.Contains(token, "=") {
+			f.lang = token
+			continue
+		}
+
+		for _, entry := range splitUnquoted(token, func(r rune) bool { return r == ',' }) {
+			key, value, found := strings.Cut(entry, "=")
+			if !found || key == "" {
+				continue
+			}
+			if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+				value = unquoted
+			}
+			f.attrs[key] = value
+		}
+	}
+
+	return f
+}
+
+// splitUnquoted splits s at the runes matching sep, except within double
+// quotes, e.g. title="cmd/main.go, v2". Empty fields are dropped.
+func splitUnquoted(s string, sep func(rune) bool) []string {
+	quoted, escaped := false, false
+	return strings.FieldsFunc(s, func(r rune) bool {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		}
+		return !quoted && sep(r)
+	})
+}
+
+// parseLineRanges turns a selection like "1,3-5" into sorted, non-overlapping
+// ranges. Lines outside of 1..maxLine, reversed and malformed entries are
+// dropped.
+func parseLineRanges(s string, maxLine int) [][2]int {
+	lines := map[int]bool{}
+	for _, entry := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(entry, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(to)
+			if err != nil {
+				continue
+			}
+		}
+		for l := max(start, 1); l <= min(end, maxLine); l++ {
+			lines[l] = true
+		}
+	}
+
+	sorted := make([]int, 0, len(lines))
+	for l := range lines {
+		sorted = append(sorted, l)
+	}
+	sort.Ints(sorted)
+
+	var ranges [][2]int
+	for _, l := range sorted {
+		if n := len(ranges); n > 0 && ranges[n-1][1] == l-1 {
+			ranges[n-1][1] = l
+		} else {
+			ranges = append(ranges, [2]int{l, l})
+		}
+	}
+	return ranges
+}
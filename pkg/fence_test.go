@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLineRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		info string
+		want [][2]int
+	}{
+		{"lines and ranges", "go {1,3-5}", [][2]int{{1, 1}, {3, 5}}},
+		{"overlapping", "go {1-4,3-6,6}", [][2]int{{1, 6}}},
+		{"adjacent", "go {3,1,2}", [][2]int{{1, 3}}},
+		{"out of range", "go {0-2,9-12,20}", [][2]int{{1, 2}, {9, 10}}},
+		{"missing closing brace", "go {1,3", nil},
+		{"reversed", "go {5-3,7}", [][2]int{{7, 7}}},
+		{"malformed", "go {1-,-2,3}", [][2]int{{3, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := parseFenceInfo([]byte(tt.info))
+			if got := parseLineRanges(info.highlight, 10); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLineRanges(%q) = %v, want %v", info.highlight, got, tt.want)
+			}
+		})
+	}
+}
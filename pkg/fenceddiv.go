@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Matches the opening line of a fenced div, e.g. "::: warning" or
+	// "::: {.warning}"
+	fencedDivOpenRegex  = regexp.MustCompile(`^:{3,}\s*(?:([\w-]+)|\{\.([\w-]+)\})\s*:*\s*$`)
+	fencedDivCloseRegex = regexp.MustCompile(`^:{3,}\s*$`)
+	// Marker of blockquotes converted from fenced divs without alert type
+	fencedDivMarkerRegex = regexp.MustCompile(`^\[!DIV ([\w-]+)\]`)
+	codeFenceRegex       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// expandFencedDivs converts the fenced divs of the document into blockquotes,
+// so that their content is parsed as markdown. Divs of an alert type become
+// alerts, the others are marked with [!DIV class]. Unclosed divs are kept as
+// they are.
+func (m Parser) expandFencedDivs(input []byte) []byte {
+	lines := strings.Split(string(input), "\n")
+
+	// Pair the opening and closing lines, outside of code blocks
+	classes := map[int]string{}
+	closing := map[int]bool{}
+	var open []int
+	var fence string
+	for i, line := range lines {
+		if match := codeFenceRegex.FindStringSubmatch(line); match != nil {
+			if fence == "" {
+				fence = match[1]
+			} else if match[1][0] == fence[0] && len(match[1]) >= len(fence) {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if match := fencedDivOpenRegex.FindStringSubmatch(line); match != nil {
+			classes[i] = match[1] + match[2]
+			open = append(open, i)
+		} else if fencedDivCloseRegex.MatchString(line) && len(open) > 0 {
+			closing[i] = true
+			open = open[:len(open)-1]
+		}
+	}
+	for _, i := range open {
+		delete(classes, i)
+	}
+	if len(closing) == 0 {
+		return input
+	}
+
+	depth := 0
+	for i, line := range lines {
+		prefix := strings.Repeat("> ", depth)
+		switch {
+		case closing[i]:
+			depth--
+			// Blockquotes separated by blank lines are merged, a separator
+			// ends the blockquote of the div
+			blank := strings.TrimSpace(strings.Repeat("> ", depth))
+			lines[i] = blank + "\n" + strings.Repeat("> ", depth) + blockquoteSeparator + "\n" + blank
+		case classes[i] != "":
+			lines[i] = prefix + "> " + m.fencedDivMarker(classes[i])
+			depth++
+		case depth > 0:
+			lines[i] = prefix + line
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// fencedDivMarker returns the marker of the blockquote of a fenced div
+func (m Parser) fencedDivMarker(class string) string {
+	for _, b := range append(blockquotes, m.opts.ExtraAlertTypes...) {
+		if strings.EqualFold(class, b) {
+			return "[!" + strings.ToUpper(b) + "]"
+		}
+	}
+	return "[!DIV " + class + "]"
+}
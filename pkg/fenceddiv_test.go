@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFencedDivs(t *testing.T) {
+	// Raw HTML is enabled, as in the CLI, so that separators would show up
+	p, err := NewParserWithDefaults(WithFencedDivs(true), WithRawHTMLEnabled(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		// Substrings which must not be part of the output
+		banned []string
+	}{
+		{
+			name:   "alert",
+			input:  "::: warning\ntext\n:::\n",
+			want:   []string{"markdown-alert-warning", "<p>text</p>\n</div>"},
+			banned: []string{":::", "<!--"},
+		},
+		{
+			name:   "nested",
+			input:  "::: note\nouter\n\n::: {.box}\ninner\n:::\n\nafter\n:::\n",
+			want:   []string{"markdown-alert-note", `<div class="fenced-div box">` + "\n<p>inner</p>\n</div>\n<p>after</p>\n</div>"},
+			banned: []string{":::", "<!--"},
+		},
+		{
+			name:   "consecutive",
+			input:  "::: box\na\n:::\n\n::: box\nb\n:::\n",
+			want:   []string{"<p>a</p>\n</div>" + `<div class="fenced-div box">` + "\n<p>b</p>\n</div>"},
+			banned: []string{"<!--"},
+		},
+		{
+			name:   "unclosed",
+			input:  "::: warning\ntext\n",
+			want:   []string{"<p>::: warning\ntext</p>"},
+			banned: []string{"markdown-alert"},
+		},
+		{
+			name:   "in code block",
+			input:  "```\n::: note\ncode\n:::\n```\n",
+			want:   []string{"::: note"},
+			banned: []string{"markdown-alert"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.MdToHTML([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("output lacks %q:\n%s", want, out)
+				}
+			}
+			for _, banned := range tt.banned {
+				if strings.Contains(string(out), banned) {
+					t.Errorf("output contains %q:\n%s", banned, out)
+				}
+			}
+		})
+	}
+}
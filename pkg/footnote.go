@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// renderHookFootnoteRef renders a reference to a footnote like GitHub
+func renderHookFootnoteRef(w io.Writer, link *ast.Link, entering bool) (ast.WalkStatus, bool, error) {
+	if !entering {
+		return ast.GoToNext, true, nil
+	}
+	slug := html.Slugify(link.Destination)
+	_, err := fmt.Fprintf(w, `<sup><a id="fnref:%s" href="#fn:%s">%d</a></sup>`, slug, slug, link.NoteID)
+	return ast.GoToNext, true, err
+}
+
+// renderHookFootnoteList renders the footnotes at the end of the document
+func renderHookFootnoteList(w io.Writer, entering bool) (ast.WalkStatus, bool, error) {
+	s := "</ol>\n</section>\n"
+	if entering {
+		s = "<section class=\"footnotes\">\n<ol>\n"
+	}
+	_, err := io.WriteString(w, s)
+	return ast.GoToNext, true, err
+}
+
+// renderHookFootnoteItem renders a footnote with a link back to the
+// reference
+func renderHookFootnoteItem(w io.Writer, item *ast.ListItem, entering bool) (ast.WalkStatus, bool, error) {
+	slug := html.Slugify(item.RefLink)
+	var err error
+	if entering {
+		_, err = fmt.Fprintf(w, `<li id="fn:%s">`, slug)
+	} else {
+		_, err = fmt.Fprintf(w, " <a href=\"#fnref:%s\" class=\"footnote-backref\" aria-label=\"Back to reference\">↩</a></li>\n", slug)
+	}
+	return ast.GoToNext, true, err
+}
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseFrontMatter separates a leading YAML (---), TOML (+++) or JSON ({)
+// front matter block from the document and decodes it. Without one the
+// metadata is nil and the body is the whole input.
+func ParseFrontMatter(input []byte) (meta map[string]any, body []byte, err error) {
+	fence, front, body := splitFrontMatter(input)
+	meta, err = parseFrontMatter(fence, front)
+	return meta, body, err
+}
+
+// splitFrontMatter separates a leading YAML (---), TOML (+++) or JSON ({)
+// front matter block from the document. Without one the fence is empty.
+func splitFrontMatter(input []byte) (fence string, front, body []byte) {
+	if bytes.HasPrefix(input, []byte("{")) {
+		return splitJSONFrontMatter(input)
+	}
+
+	first, rest, ok := bytes.Cut(input, []byte("\n"))
+	fence = string(bytes.TrimRight(first, " \t\r"))
+	if !ok || (fence != "---" && fence != "+++") {
+		return "", nil, input
+	}
+
+	offset := len(first) + 1
+	for len(rest) > 0 {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+		closing := string(bytes.TrimRight(line, " \t\r"))
+		if closing == fence || (fence == "---" && closing == "...") {
+			return fence, input[len(first)+1 : offset], next
+		}
+		offset += len(line) + 1
+		rest = next
+	}
+	// Without a closing fence it is no front matter
+	return "", nil, input
+}
+
+// splitJSONFrontMatter separates a leading JSON object, which must end a
+// line, from the document
+func splitJSONFrontMatter(input []byte) (fence string, front, body []byte) {
+	dec := json.NewDecoder(bytes.NewReader(input))
+	var object map[string]json.RawMessage
+	if err := dec.Decode(&object); err != nil {
+		return "", nil, input
+	}
+
+	end := int(dec.InputOffset())
+	rest := bytes.TrimLeft(input[end:], " \t\r")
+	if len(rest) > 0 && rest[0] != '\n' {
+		return "", nil, input
+	}
+	return "{", input[:end], bytes.TrimPrefix(rest, []byte("\n"))
+}
+
+// parseFrontMatter decodes front matter separated by splitFrontMatter
+func parseFrontMatter(fence string, front []byte) (map[string]any, error) {
+	var meta map[string]any
+	var err error
+	switch fence {
+	case "":
+		return nil, nil
+	case "{":
+		err = json.Unmarshal(front, &meta)
+	case "+++":
+		err = toml.Unmarshal(front, &meta)
+	default:
+		err = yaml.Unmarshal(front, &meta)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("front matter: %w", err)
+	}
+	return meta, nil
+}
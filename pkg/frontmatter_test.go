@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]any
+	}{
+		{
+			name:  "yaml nested map",
+			input: "---\nauthor:\n  name: Jane\n  email: jane@example.com\n---\n# Body\n",
+			want:  map[string]any{"author": map[string]any{"name": "Jane", "email": "jane@example.com"}},
+		},
+		{
+			name:  "yaml inline comment",
+			input: "---\ntitle: Hello # not part of the title\ntags: [a, b]\n---\n",
+			want:  map[string]any{"title": "Hello", "tags": []any{"a", "b"}},
+		},
+		{
+			name:  "yaml block scalar",
+			input: "---\ndescription: |\n  first line\n  second line\n---\n",
+			want:  map[string]any{"description": "first line\nsecond line\n"},
+		},
+		{
+			name:  "toml table",
+			input: "+++\ntitle = \"Hello\" # comment\n[author]\nname = \"Jane\"\n+++\n",
+			want:  map[string]any{"title": "Hello", "author": map[string]any{"name": "Jane"}},
+		},
+		{
+			name:  "json",
+			input: "{\"title\": \"Hello\"}\n# Body\n",
+			want:  map[string]any{"title": "Hello"},
+		},
+		{
+			name:  "none",
+			input: "# Body\n",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, _, err := ParseFrontMatter([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(meta, tt.want) {
+				t.Errorf("got %#v, want %#v", meta, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatterInvalid(t *testing.T) {
+	if _, _, err := ParseFrontMatter([]byte("---\ntitle: [unclosed\n---\n")); err == nil {
+		t.Error("want an error for invalid YAML")
+	}
+}
@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Path of the code highlighting stylesheet served with ServeCSS
+const handlerCSSPath = "/.grip/style.css"
+
+type httpHandler struct {
+	parser *Parser
+	fsys   fs.FS
+	// Last rendered page by path
+	cache sync.Map
+}
+
+// cachedPage is a page rendered from the file with the modification time
+type cachedPage struct {
+	modTime time.Time
+	page    []byte
+}
+
+// NewHTTPHandler returns a handler rendering the markdown files of ServeFS as
+// HTML pages, e.g. /docs/README.md. Other files are not found.
+func NewHTTPHandler(opts ParserOptions, options ...Option) (http.Handler, error) {
+	parser, err := NewParser(opts, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := parser.opts.ServeFS
+	if fsys == nil {
+		fsys = os.DirFS(".")
+	}
+	return &httpHandler{parser: parser, fsys: fsys}, nil
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.parser.opts.ServeCSS && r.URL.Path == handlerCSSPath {
+		h.serveCSS(w)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if !fs.ValidPath(name) || !strings.EqualFold(path.Ext(name), ".md") {
+		http.NotFound(w, r)
+		return
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// A changed file replaces the page of its previous version
+	cached, ok := h.cache.Load(name)
+	if !ok || !cached.(cachedPage).modTime.Equal(info.ModTime()) {
+		page, err := h.render(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cached = cachedPage{modTime: info.ModTime(), page: page}
+		h.cache.Store(name, cached)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(cached.(cachedPage).page)
+}
+
+func (h *httpHandler) render(name string) ([]byte, error) {
+	input, err := fs.ReadFile(h.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := PageOptions{
+		Title:           path.Base(name),
+		BodyClass:       "markdown-body",
+		InlineChromaCSS: !h.parser.opts.ServeCSS,
+	}
+	if h.parser.opts.ServeCSS {
+		opts.Stylesheet = handlerCSSPath
+	}
+
+	page, err := h.parser.MdToHTMLPage(input, opts)
+	if page == nil {
+		return nil, err
+	}
+	// The page is still usable, e.g. with a diagram shown as source
+	if err != nil {
+		log.Println("Warning:", name+":", err)
+	}
+	return page, nil
+}
+
+func (h *httpHandler) serveCSS(w http.ResponseWriter) {
+	light, dark, err := h.parser.chromaCSS()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	css := string(light) + string(dark)
+	if light != "" && dark != "" {
+		css = "@media (prefers-color-scheme: light) {\n" + string(light) + "}\n" +
+			"@media (prefers-color-scheme: dark) {\n" + string(dark) + "}\n"
+	}
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	_, _ = w.Write([]byte(css))
+}
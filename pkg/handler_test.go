@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/README.md": {Data: []byte("# First\n"), ModTime: time.Unix(1, 0)},
+	}
+	h, err := NewHTTPHandler(DefaultParserOptions(), WithServeFS(fsys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/docs/README.md"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "First") {
+		t.Errorf("got %d:\n%s", rec.Code, rec.Body)
+	}
+	if rec := get("/docs/missing.md"); rec.Code != http.StatusNotFound {
+		t.Errorf("missing file: got %d, want 404", rec.Code)
+	}
+
+	// An edited file is rendered again and replaces the cached page
+	fsys["docs/README.md"] = &fstest.MapFile{Data: []byte("# Second\n"), ModTime: time.Unix(2, 0)}
+	if rec := get("/docs/README.md"); !strings.Contains(rec.Body.String(), "Second") {
+		t.Errorf("edited file not rendered again:\n%s", rec.Body)
+	}
+	entries := 0
+	h.(*httpHandler).cache.Range(func(key, value any) bool {
+		entries++
+		return true
+	})
+	if entries != 1 {
+		t.Errorf("got %d cache entries, want 1", entries)
+	}
+}
+
+func TestHTTPHandlerInvalidOptions(t *testing.T) {
+	if _, err := NewHTTPHandler(DefaultParserOptions(), WithChromaTheme("no-such-theme")); err == nil {
+		t.Error("want the error of NewParser")
+	}
+}
@@ -0,0 +1,203 @@
+package pkg
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// Matches a trailing attribute list like {.class #id}
+var headingAttributesRegex = regexp.MustCompile(`\s*\{((?:\s*[.#][\w-]+)+)\s*\}\s*$`)
+
+const defaultHeadingPermalinkIcon = "¶"
+
+type Heading struct {
+	Level int
+	Text  string
+	ID    string
+}
+
+// ExtractHeadings returns the headings of the document with the same IDs as
+// used by MdToHTML, without rendering it
+func (m Parser) ExtractHeadings(input []byte) []Heading {
+	doc := m.parse(input)
+	m.assignHeadingIDs(doc)
+
+	headings := collectHeadings(doc)
+	for i := range headings {
+		if headings[i].ID != "" {
+			headings[i].ID = m.opts.HeadingIDPrefix + headings[i].ID
+		}
+	}
+	return headings
+}
+
+// assignHeadingIDs sets the unique heading IDs the renderer would use, but
+// without HeadingIDPrefix as rendering adds it
+func (m Parser) assignHeadingIDs(doc ast.Node) {
+	renderer := html.NewRenderer(html.RendererOptions{})
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			renderer.MakeUniqueHeadingID(heading)
+		}
+		return ast.GoToNext
+	})
+}
+
+// applyHeadingAttributes moves a trailing attribute list of headings like
+// {.class #id} into the classes and the ID of the heading
+func applyHeadingAttributes(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+
+		var attributes []string
+		if strings.ContainsAny(heading.HeadingID, " \t") {
+			// The HeadingIDs extension takes {#id .class} as a whole as ID
+			attributes = strings.Fields("#" + heading.HeadingID)
+			heading.HeadingID = ""
+		} else if t, ok := ast.GetLastChild(heading).(*ast.Text); ok {
+			if match := headingAttributesRegex.FindSubmatch(t.Literal); match != nil {
+				attributes = strings.Fields(string(match[1]))
+				t.Literal = t.Literal[:len(t.Literal)-len(match[0])]
+				// Auto IDs were generated including the attribute list
+				if id, ok := strings.CutSuffix(heading.HeadingID, "-"+slugify(string(match[1]))); ok {
+					heading.HeadingID = id
+				}
+			}
+		}
+
+		for _, attribute := range attributes {
+			switch attribute[0] {
+			case '#':
+				heading.HeadingID = attribute[1:]
+			case '.':
+				if heading.Attribute == nil {
+					heading.Attribute = &ast.Attribute{}
+				}
+				heading.Classes = append(heading.Classes, []byte(attribute[1:]))
+			}
+		}
+		return ast.GoToNext
+	})
+}
+
+// slugify generates heading IDs the same way as the markdown parser
+func slugify(text string) string {
+	var id []rune
+	dash := false
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			dash = true
+			continue
+		}
+		if dash && len(id) > 0 {
+			id = append(id, '-')
+		}
+		dash = false
+		id = append(id, unicode.ToLower(r))
+	}
+	if len(id) == 0 {
+		return "empty"
+	}
+	return string(id)
+}
+
+// sanitizeHeadingIDs removes characters from heading IDs which are not allowed
+// in an id attribute. Custom IDs like {#my-id} are used verbatim otherwise.
+func sanitizeHeadingIDs(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if heading, ok := node.(*ast.Heading); ok && entering {
+			heading.HeadingID = strings.Map(func(r rune) rune {
+				switch {
+				case unicode.IsSpace(r):
+					return '-'
+				case unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.:", r):
+					return r
+				}
+				return -1
+			}, heading.HeadingID)
+		}
+		return ast.GoToNext
+	})
+}
+
+// collectHeadings returns the headings of a rendered document
+func collectHeadings(doc ast.Node) []Heading {
+	var headings []Heading
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		headings = append(headings, newHeading(heading))
+		return ast.SkipChildren
+	})
+	return headings
+}
+
+func newHeading(heading *ast.Heading) Heading {
+	return Heading{
+		Level: heading.Level,
+		Text:  strings.TrimSpace(nodeText(heading)),
+		ID:    heading.HeadingID,
+	}
+}
+
+// nodeText returns the plain text content of a node
+func nodeText(node ast.Node) string {
+	var text strings.Builder
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if _, isHTML := n.(*ast.HTMLSpan); isHTML {
+			return ast.GoToNext
+		}
+		if leaf := n.AsLeaf(); leaf != nil && entering {
+			text.Write(leaf.Literal)
+		}
+		return ast.GoToNext
+	})
+	return text.String()
+}
+
+func (r *renderState) renderHookHeading(w io.Writer, heading *ast.Heading, entering bool) (ast.WalkStatus, bool, error) {
+	if !r.parser.opts.HeadingPermalinks {
+		return ast.GoToNext, false, nil
+	}
+	after := r.parser.opts.HeadingPermalinkPosition == "after"
+
+	var err error
+	if entering {
+		// Assigns the final ID of the heading
+		r.renderer.HeadingEnter(w, heading)
+		if !after {
+			err = r.parser.writePermalink(w, heading)
+		}
+	} else {
+		if after {
+			err = r.parser.writePermalink(w, heading)
+		}
+		r.renderer.HeadingExit(w, heading)
+	}
+	return ast.GoToNext, true, err
+}
+
+func (m Parser) writePermalink(w io.Writer, heading *ast.Heading) error {
+	if heading.HeadingID == "" {
+		return nil
+	}
+	icon := m.opts.HeadingPermalinkIcon
+	if icon == "" {
+		icon = defaultHeadingPermalinkIcon
+	}
+	_, err := fmt.Fprintf(w, `<a class="anchor" href="#%s" aria-hidden="true">%s</a>`,
+		template.HTMLEscapeString(heading.HeadingID), icon)
+	return err
+}
@@ -0,0 +1,20 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadingPermalinkIcon(t *testing.T) {
+	p, err := NewParserWithDefaults(WithHeadingPermalinks(true), WithHeadingPermalinkIcon(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := p.MdToHTML([]byte("# Title\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `aria-hidden="true">¶</a>`) {
+		t.Errorf("want the default icon:\n%s", out)
+	}
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+func (r *renderState) renderHookImage(w io.Writer, image *ast.Image, entering bool) (ast.WalkStatus, bool, error) {
+	// Images within text are kept inline
+	paragraph, ok := image.GetParent().(*ast.Paragraph)
+	if !ok || !r.parser.isFigure(paragraph) {
+		return ast.GoToNext, false, nil
+	}
+
+	if entering {
+		if _, err := io.WriteString(w, "<figure>"); err != nil {
+			return ast.GoToNext, true, err
+		}
+		r.renderer.Image(w, image, true)
+		return ast.GoToNext, true, nil
+	}
+
+	// The title is shown as caption instead of a tooltip
+	title := image.Title
+	image.Title = nil
+	r.renderer.Image(w, image, false)
+	image.Title = title
+
+	_, err := fmt.Fprintf(w, "<figcaption>%s</figcaption></figure>", template.HTMLEscapeString(string(title)))
+	return ast.GoToNext, true, err
+}
+
+// isFigure reports whether the paragraph only consists of an image rendered as
+// figure, which takes the place of the paragraph
+func (m Parser) isFigure(paragraph *ast.Paragraph) bool {
+	if !m.opts.ImageFigure {
+		return false
+	}
+	figure := false
+	for _, child := range paragraph.GetChildren() {
+		switch child := child.(type) {
+		case *ast.Image:
+			if figure || len(child.Title) == 0 {
+				return false
+			}
+			figure = true
+		case *ast.Text:
+			if len(bytes.TrimSpace(child.Literal)) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return figure
+}
@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImageFigure(t *testing.T) {
+	p, err := NewParserWithDefaults(WithImageFigure(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "alone",
+			input: "![alt](a.png \"Caption\")\n",
+			want:  `<figure><img loading="lazy" src="a.png" alt="alt" /><figcaption>Caption</figcaption></figure>`,
+		},
+		{
+			name:  "inline",
+			input: "text ![alt](a.png \"Caption\") text\n",
+			want:  `<p>text <img loading="lazy" src="a.png" alt="alt" title="Caption" /> text</p>`,
+		},
+		{
+			name:  "without title",
+			input: "![alt](a.png)\n",
+			want:  `<p><img loading="lazy" src="a.png" alt="alt" /></p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.MdToHTML([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
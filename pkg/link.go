@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+type Link struct {
+	Text       string
+	URL        string
+	IsImage    bool
+	IsExternal bool
+}
+
+// ExtractLinks returns all links and images of the document. Autolinks are
+// parsed into regular links and therefore included as well.
+func (m Parser) ExtractLinks(input []byte) []Link {
+	var links []Link
+	ast.WalkFunc(m.parse(input), func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Link:
+			// Footnote references are no hyperlinks
+			if node.NoteID != 0 {
+				return ast.GoToNext
+			}
+			links = append(links, m.newLink(node, node.Destination, false))
+		case *ast.Image:
+			links = append(links, m.newLink(node, node.Destination, true))
+		}
+		return ast.GoToNext
+	})
+	return links
+}
+
+func (m Parser) newLink(node ast.Node, destination []byte, image bool) Link {
+	return Link{
+		Text:       strings.TrimSpace(nodeText(node)),
+		URL:        string(destination),
+		IsImage:    image,
+		IsExternal: m.isExternal(string(destination)),
+	}
+}
+
+// resolveURLs makes the relative destinations of links and images absolute
+// using BaseURL
+func (m Parser) resolveURLs(doc ast.Node) {
+	if m.opts.BaseURL == nil {
+		return
+	}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Link:
+			if node.NoteID == 0 {
+				node.Destination = m.resolveURL(node.Destination)
+			}
+		case *ast.Image:
+			node.Destination = m.resolveURL(node.Destination)
+		}
+		return ast.GoToNext
+	})
+}
+
+func (m Parser) resolveURL(destination []byte) []byte {
+	s := string(destination)
+	// Fragments refer to the document itself
+	if s == "" || strings.HasPrefix(s, "#") || isExternalURL(s) {
+		return destination
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return destination
+	}
+	return []byte(m.opts.BaseURL.ResolveReference(u).String())
+}
+
+// setLinkAttributes protects external links against reverse tabnapping and
+// opens them in a new tab with ExternalLinksNewTab
+func (m Parser) setLinkAttributes(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		link, ok := node.(*ast.Link)
+		if !ok || !entering || link.NoteID != 0 || bytes.HasPrefix(link.Destination, []byte("mailto:")) {
+			return ast.GoToNext
+		}
+		if m.isExternal(string(link.Destination)) {
+			link.AdditionalAttributes = append(link.AdditionalAttributes, `rel="noopener noreferrer"`)
+			if m.opts.ExternalLinksNewTab {
+				link.AdditionalAttributes = append(link.AdditionalAttributes, `target="_blank"`)
+			}
+		}
+		return ast.GoToNext
+	})
+}
+
+// isExternal reports whether the url points to another site than BaseURL
+func (m Parser) isExternal(s string) bool {
+	if !isExternalURL(s) {
+		return false
+	}
+	if m.opts.BaseURL == nil {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err != nil || u.Host != m.opts.BaseURL.Host
+}
+
+// isExternalURL reports whether the url points to another site, in contrast
+// to anchors and relative paths
+func isExternalURL(s string) bool {
+	if strings.HasPrefix(s, "//") {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
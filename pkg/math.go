@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// Templates of the scripts rendering the math in the browser
+var mathEngineTemplates = map[string]string{
+	"mathjax": "templates/math/mathjax.html",
+	"katex":   "templates/math/katex.html",
+}
+
+// renderMathScript writes the script of the math engine in front of the
+// document if it contains math, outside of its paragraphs
+func (r *renderState) renderMathScript(w io.Writer, doc ast.Node, entering bool) (ast.WalkStatus, bool, error) {
+	name, ok := mathEngineTemplates[r.parser.opts.MathEngine]
+	if !ok || !entering || !containsMath(doc) {
+		return ast.GoToNext, false, nil
+	}
+
+	tmpl, err := r.parser.parseTemplate(name)
+	if err != nil {
+		return ast.GoToNext, false, err
+	}
+	return ast.GoToNext, false, tmpl.Execute(w, nil)
+}
+
+// containsMath reports whether the document has inline or display math
+func containsMath(doc ast.Node) bool {
+	found := false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node.(type) {
+		case *ast.Math, *ast.MathBlock:
+			found = true
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return found
+}
+
+// renderHookMath writes the math with the delimiters of MathJax and KaTeX
+func (r *renderState) renderHookMath(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool, error) {
+	if _, ok := mathEngineTemplates[r.parser.opts.MathEngine]; !ok {
+		return ast.GoToNext, false, nil
+	}
+	if !entering {
+		return ast.GoToNext, true, nil
+	}
+
+	open, close := `<span class="math inline">\(`, `\)</span>`
+	var literal []byte
+	switch node := node.(type) {
+	case *ast.Math:
+		literal = node.Literal
+	case *ast.MathBlock:
+		open, close = `<div class="math display">\[`, `\]</div>`
+		literal = node.Literal
+	}
+	// EscapeHTML drops the errors of the writer
+	var escaped bytes.Buffer
+	html.EscapeHTML(&escaped, literal)
+	_, err := io.WriteString(w, open+escaped.String()+close)
+	return ast.SkipChildren, true, err
+}
@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"github.com/tdewolff/minify/v2"
+	minify_html "github.com/tdewolff/minify/v2/html"
+)
+
+// htmlMinifier collapses whitespace and drops optional quotes and default
+// attribute values. The content of <pre> and <textarea> is kept, comments are
+// left to StripComments and end tags are kept, as the HTML is often embedded
+// into other pages.
+var htmlMinifier = func() *minify.M {
+	m := minify.New()
+	m.Add("text/html", &minify_html.Minifier{
+		KeepComments:     true,
+		KeepDocumentTags: true,
+		KeepEndTags:      true,
+	})
+	return m
+}()
+
+// minifyHTML minifies the HTML, on errors it is returned unchanged
+func minifyHTML(input []byte) ([]byte, error) {
+	out, err := htmlMinifier.Bytes("text/html", input)
+	if err != nil {
+		return input, err
+	}
+	return out, nil
+}
@@ -0,0 +1,21 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMinifyHTML(t *testing.T) {
+	input := "<p>some   text\n  here</p>\n\n<pre><code>func  main() {\n    x  :=  1\n}\n</code></pre>\n"
+	out, err := minifyHTML([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Runs of whitespace become a single space or newline
+	if !strings.Contains(string(out), "<p>some text\nhere</p>") {
+		t.Errorf("whitespace of the text not collapsed:\n%s", out)
+	}
+	if !strings.Contains(string(out), "<pre><code>func  main() {\n    x  :=  1\n}\n</code></pre>") {
+		t.Errorf("whitespace of <pre> changed:\n%s", out)
+	}
+}
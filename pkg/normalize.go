@@ -0,0 +1,436 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Matches the start of a line which would be parsed as block, e.g. "# " or
+// "1. "
+var blockStartRegex = regexp.MustCompile(`^([#>+-]|\d+[.)]|=+|-+)(?:\s|$)`)
+
+// Normalize reformats the document as canonical markdown: ATX headings,
+// fenced code blocks, "-" and "1." list markers and a trailing newline. The
+// document renders the same as before.
+func (m Parser) Normalize(input []byte) ([]byte, error) {
+	fence, front, body := splitFrontMatter(input)
+	// Only the explicit heading IDs must be written
+	doc := parser.NewWithExtensions(m.extensions() &^ parser.AutoHeadingIDs).Parse(separateBlockquotes(body))
+
+	var n normalizer
+	out, err := n.blocks(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	switch fence {
+	case "":
+	case "{":
+		b.WriteString(string(front) + "\n\n")
+	default:
+		b.WriteString(fence + "\n" + string(front) + fence + "\n\n")
+	}
+	if out != "" {
+		b.WriteString(out + "\n")
+	}
+	return []byte(b.String()), nil
+}
+
+type normalizer struct {
+	// Depth of blockquotes around the current block
+	quotes int
+}
+
+// blocks returns the block children of the node separated by empty lines
+func (n *normalizer) blocks(node ast.Node) (string, error) {
+	var blocks []string
+	var prev ast.Node
+	for _, child := range node.GetChildren() {
+		block, err := n.block(child, prev)
+		if err != nil {
+			return "", err
+		}
+		if block != "" {
+			blocks = append(blocks, block)
+		}
+		prev = child
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+func (n *normalizer) block(node, prev ast.Node) (string, error) {
+	switch node := node.(type) {
+	case *ast.Paragraph:
+		return normalizeInline(node)
+	case *ast.Heading:
+		text, err := normalizeInline(node)
+		if err != nil {
+			return "", err
+		}
+		if node.HeadingID != "" {
+			text += " {#" + node.HeadingID + "}"
+		}
+		return strings.Repeat("#", node.Level) + " " + text, nil
+	case *ast.HorizontalRule:
+		return "---", nil
+	case *ast.CodeBlock:
+		code := strings.TrimSuffix(string(node.Literal), "\n")
+		fence := codeFence(code, '`', 3)
+		if n.quotes > 0 {
+			// gomarkdown closes backtick fences in blockquotes with later
+			// fences outside of them
+			fence = codeFence(code, '~', 3)
+		}
+		return fence + string(node.Info) + "\n" + code + "\n" + fence, nil
+	case *ast.MathBlock:
+		return "$$\n" + strings.Trim(string(node.Literal), "\n") + "\n$$", nil
+	case *ast.HTMLBlock:
+		// Blockquotes separated by a blank line stay separate
+		if isBlockquoteSeparator(node) {
+			return "", nil
+		}
+		return strings.TrimRight(string(node.Literal), "\n"), nil
+	case *ast.BlockQuote:
+		n.quotes++
+		content, err := n.blocks(node)
+		n.quotes--
+		if err != nil {
+			return "", err
+		}
+		return prefixLines(content, "> ", ">"), nil
+	case *ast.Footnotes:
+		// The footnotes follow as list
+		return "", nil
+	case *ast.List:
+		if node.IsFootnotesList {
+			return n.footnotes(node)
+		}
+		if node.ListFlags&ast.ListTypeDefinition != 0 {
+			return n.definitions(node)
+		}
+		return n.list(node, prev)
+	case *ast.Table:
+		return normalizeTable(node)
+	}
+	return "", fmt.Errorf("normalize: unsupported node %T", node)
+}
+
+func (n *normalizer) list(list *ast.List, prev ast.Node) (string, error) {
+	ordered := list.ListFlags&ast.ListTypeOrdered != 0
+	// Adjacent lists need different markers to stay separate
+	bullet, delimiter := "-", "."
+	if prevList, ok := prev.(*ast.List); ok && (prevList.ListFlags&ast.ListTypeOrdered != 0) == ordered {
+		bullet, delimiter = "*", ")"
+	}
+	number := max(list.Start, 1)
+
+	separator := "\n\n"
+	if list.Tight {
+		separator = "\n"
+	}
+
+	var items []string
+	for _, child := range list.GetChildren() {
+		item, ok := child.(*ast.ListItem)
+		if !ok {
+			return "", fmt.Errorf("normalize: unsupported node %T in list", child)
+		}
+
+		var blocks []string
+		var prevBlock ast.Node
+		for _, block := range item.GetChildren() {
+			content, err := n.block(block, prevBlock)
+			if err != nil {
+				return "", err
+			}
+			blocks = append(blocks, content)
+			prevBlock = block
+		}
+
+		marker := bullet + " "
+		if ordered {
+			marker = strconv.Itoa(number) + delimiter + " "
+			number++
+		}
+		content := strings.Join(blocks, separator)
+		indented := prefixLines(content, strings.Repeat(" ", len(marker)), "")
+		items = append(items, marker+strings.TrimLeft(indented, " "))
+	}
+	return strings.Join(items, separator), nil
+}
+
+// definitions returns the terms of the definition list, each followed by its
+// definitions starting with ":"
+func (n *normalizer) definitions(list *ast.List) (string, error) {
+	separator := "\n\n"
+	if list.Tight {
+		separator = "\n"
+	}
+
+	var b strings.Builder
+	for i, child := range list.GetChildren() {
+		item, ok := child.(*ast.ListItem)
+		if !ok {
+			return "", fmt.Errorf("normalize: unsupported node %T in definition list", child)
+		}
+		content, err := n.blocks(item)
+		if err != nil {
+			return "", err
+		}
+
+		if item.ListFlags&ast.ListTypeTerm != 0 {
+			if i > 0 {
+				b.WriteString("\n\n")
+			}
+			b.WriteString(content)
+			continue
+		}
+		b.WriteString(separator + ":   " + strings.TrimLeft(prefixLines(content, "    ", ""), " "))
+	}
+	return b.String(), nil
+}
+
+// footnotes returns the footnote definitions of the list, e.g. "[^1]: Note"
+func (n *normalizer) footnotes(list *ast.List) (string, error) {
+	var notes []string
+	for _, child := range list.GetChildren() {
+		item, ok := child.(*ast.ListItem)
+		if !ok || item.RefLink == nil {
+			return "", fmt.Errorf("normalize: unsupported node %T in footnotes", child)
+		}
+
+		// Single line footnotes consist of inline nodes only
+		var content string
+		var err error
+		if _, isBlock := ast.GetFirstChild(item).(*ast.Paragraph); isBlock {
+			content, err = n.blocks(item)
+		} else {
+			content, err = normalizeInline(item)
+		}
+		if err != nil {
+			return "", err
+		}
+		notes = append(notes, "[^"+string(item.RefLink)+"]: "+strings.TrimLeft(prefixLines(content, "    ", ""), " "))
+	}
+	return strings.Join(notes, "\n"), nil
+}
+
+func normalizeTable(table *ast.Table) (string, error) {
+	var lines []string
+	ast.WalkFunc(table, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+
+		var cells, aligns []string
+		for _, child := range row.GetChildren() {
+			cell, ok := child.(*ast.TableCell)
+			if !ok {
+				continue
+			}
+			content, err := normalizeInline(cell)
+			if err != nil {
+				lines = nil
+				return ast.Terminate
+			}
+			cells = append(cells, content)
+			aligns = append(aligns, map[ast.CellAlignFlags]string{
+				ast.TableAlignmentLeft:   ":--",
+				ast.TableAlignmentRight:  "--:",
+				ast.TableAlignmentCenter: ":-:",
+			}[cell.Align])
+		}
+
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if _, isHeader := row.GetParent().(*ast.TableHeader); isHeader && len(lines) == 1 {
+			for i, align := range aligns {
+				if align == "" {
+					aligns[i] = "---"
+				}
+			}
+			lines = append(lines, "| "+strings.Join(aligns, " | ")+" |")
+		}
+		return ast.SkipChildren
+	})
+	if lines == nil {
+		return "", fmt.Errorf("normalize: unsupported table")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// normalizeInline returns the inline children of the node
+func normalizeInline(node ast.Node) (string, error) {
+	// Pipes in text would split table cells, code spans are kept together
+	pipes := inTableCell(node)
+	var b strings.Builder
+	// Escapes split the text, so adjacent texts are escaped together
+	var text strings.Builder
+	children := node.GetChildren()
+	for i, child := range children {
+		if t, ok := child.(*ast.Text); ok {
+			text.Write(t.Literal)
+			if i+1 < len(children) {
+				if _, nextText := children[i+1].(*ast.Text); nextText {
+					continue
+				}
+			}
+			lineStart := b.Len() == 0 || strings.HasSuffix(b.String(), "\n")
+			b.WriteString(escapeMarkdown(text.String(), lineStart, pipes))
+			text.Reset()
+			continue
+		}
+
+		switch child := child.(type) {
+		case *ast.Hardbreak:
+			b.WriteString("\\\n")
+		case *ast.Softbreak:
+			b.WriteString("\n")
+		case *ast.HTMLSpan:
+			b.Write(child.Literal)
+		case *ast.Code:
+			fence := codeFence(string(child.Literal), '`', 1)
+			// Spaces keep backticks at the edges apart from the fence
+			padding := ""
+			if strings.HasPrefix(string(child.Literal), "`") || strings.HasSuffix(string(child.Literal), "`") {
+				padding = " "
+			}
+			b.WriteString(fence + padding + string(child.Literal) + padding + fence)
+		case *ast.Math:
+			b.WriteString("$" + string(child.Literal) + "$")
+		case *ast.Emph, *ast.Strong, *ast.Del:
+			content, err := normalizeInline(child)
+			if err != nil {
+				return "", err
+			}
+			marker := "*"
+			switch child.(type) {
+			case *ast.Strong:
+				marker = "**"
+			case *ast.Del:
+				marker = "~~"
+			}
+			b.WriteString(marker + content + marker)
+		case *ast.Link:
+			if child.NoteID != 0 {
+				b.WriteString("[^" + string(child.Destination) + "]")
+				continue
+			}
+			content, err := normalizeInline(child)
+			if err != nil {
+				return "", err
+			}
+			text, destination := nodeText(child), string(child.Destination)
+			if len(child.Title) == 0 && (text == destination || "mailto:"+text == destination) {
+				b.WriteString("<" + text + ">")
+				continue
+			}
+			b.WriteString("[" + content + "](" + normalizeDestination(child.Destination, child.Title) + ")")
+		case *ast.Image:
+			// The alt text is not parsed as markdown
+			b.WriteString("![" + nodeText(child) + "](" + normalizeDestination(child.Destination, child.Title) + ")")
+		default:
+			return "", fmt.Errorf("normalize: unsupported node %T", child)
+		}
+	}
+	return b.String(), nil
+}
+
+// normalizeDestination returns the destination and title of a link, both
+// are taken literally by the parser
+func normalizeDestination(destination, title []byte) string {
+	if len(title) > 0 {
+		return string(destination) + ` "` + string(title) + `"`
+	}
+	return string(destination)
+}
+
+// inTableCell reports whether the node is part of a table cell
+func inTableCell(node ast.Node) bool {
+	for ; node != nil; node = node.GetParent() {
+		if _, ok := node.(*ast.TableCell); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeMarkdown escapes the characters of text which would otherwise be
+// parsed as markdown, pipes only if they would end a table cell
+func escapeMarkdown(text string, lineStart, pipes bool) string {
+	// Escapes split the text, which breaks emoji and wikilinks
+	protected := make([]bool, len(text))
+	for _, regex := range []*regexp.Regexp{emojiRegex, wikiLinkRegex} {
+		for _, loc := range regex.FindAllStringIndex(text, -1) {
+			for i := loc[0]; i < loc[1]; i++ {
+				protected[i] = true
+			}
+		}
+	}
+	links := strings.Contains(text, "](") || strings.Contains(text, "][")
+
+	var b strings.Builder
+	for i, r := range text {
+		next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+		switch {
+		case protected[i]:
+		case r == '\\' || r == '*' || r == '_' || r == '`',
+			r == '<' && (next == '/' || next == '!' || unicode.IsLetter(next)),
+			r == '~' && next == '~',
+			r == '|' && pipes,
+			(r == '[' || r == ']') && links:
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		if i == 0 && !lineStart {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if loc := blockStartRegex.FindStringSubmatchIndex(line[indent:]); loc != nil {
+			// Escape the last character of the marker, e.g. "\#" or "1\."
+			at := indent + loc[3] - 1
+			lines[i] = line[:at] + `\` + line[at:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeFence returns a fence of at least min characters longer than any run
+// of the character in the code
+func codeFence(code string, char rune, min int) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == char {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat(string(char), max(min, longest+1))
+}
+
+// prefixLines prefixes every line, empty lines with the empty prefix
+func prefixLines(text, prefix, emptyPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = emptyPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,55 @@
+package pkg
+
+import "testing"
+
+func TestNormalizeRoundTrip(t *testing.T) {
+	p, err := NewParserWithDefaults(WithFootnotesEnabled(true), WithDefinitionLists(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "table",
+			input: "| a | b |\n|:--|--:|\n| `x|y` | p\\|q |\n| *a|b* | [l|k](/x) |\n",
+		},
+		{
+			name:  "alerts",
+			input: "> [!NOTE]\n> a\n\n> [!WARNING]\n> b\n\n> plain quote\n",
+		},
+		{
+			name:  "task list",
+			input: "- [ ] open\n- [x] done\n  - [ ] nested\n",
+		},
+		{
+			name:  "footnotes",
+			input: "Text[^1] and more[^note].\n\n[^1]: First\n[^note]: Second with `code`\n",
+		},
+		{
+			name:  "definition list",
+			input: "Term\n: Definition\n\nOther *term*\n: First\n: Second\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := p.Normalize([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := p.MdToHTML([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.MdToHTML(normalized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("normalized document renders differently:\n%s\ngot:\n%s\nwant:\n%s", normalized, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,559 @@
+package pkg
+
+import (
+	"html/template"
+	"io/fs"
+	"net/url"
+)
+
+type ParserOptions struct {
+	// Theme of the document [light/dark/auto]
+	Theme string
+	// Add line numbers to code blocks
+	LineNumbers bool
+	// First line number of code blocks
+	LineNumbersStart int
+	// Additional emojis, e.g. ":company-logo:" => "/assets/logo.svg". Values
+	// starting with "/" or "http" are rendered as images
+	ExtraEmoji map[string]string
+	// Height and width of image emojis in pixels, 0 leaves the size to CSS
+	EmojiSize int
+	// Add a progress bar in front of task lists
+	TaskListProgress bool
+	// Icons replacing the built-in ones of alerts, e.g. "note" => "<svg>…</svg>"
+	AlertIcons map[string]template.HTML
+	// Titles replacing the default ones of alerts, e.g. "note" => "Hinweis"
+	AlertTitles map[string]string
+	// Alert types in addition to the built-in ones, e.g. "Deprecated" for
+	// [!DEPRECATED]
+	ExtraAlertTypes []string
+	// Templates overriding the built-in ones, using the same paths, e.g.
+	// "templates/alert/note.html" or "templates/mermaid/mermaid.html"
+	TemplateFS fs.FS
+	// Deepest heading level included in the table of contents, 0 includes all
+	TOCMaxDepth int
+	// Reading speed used by ReadingTime
+	WordsPerMinute int
+	// Count the words of code blocks and inline code in WordCount and
+	// ReadingTime
+	CountCodeWords bool
+	// Decode the YAML, TOML or JSON front matter into ParseResult.Metadata. It is
+	// stripped from the output in any case
+	ParseFrontMatter bool
+	// Prefix of all heading IDs, e.g. "doc-" to avoid collisions with the IDs
+	// of the page embedding the document
+	HeadingIDPrefix string
+	// Add a link to the heading itself to every heading
+	HeadingPermalinks bool
+	// Content of the permalinks, inserted as HTML, "¶" if empty
+	HeadingPermalinkIcon string
+	// Position of the permalinks in the heading [before/after]
+	HeadingPermalinkPosition string
+	// URL of the mermaid ES module, e.g.
+	// "https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.esm.min.mjs".
+	// Empty uses the bundled mermaid.js served under /static
+	MermaidCDNURL string
+	// Path or data: URI of a mermaid.js bundle (not the ES module) for pages
+	// which must not load scripts from a CDN. Takes precedence over
+	// MermaidCDNURL
+	MermaidLocalJS string
+	// Configuration passed to mermaid.initialize, e.g. "fontSize" => 16. It
+	// overrides the theme derived from Theme
+	MermaidConfig map[string]any
+	// Renderers of diagram languages, taking precedence over the built-in
+	// mermaid support
+	DiagramRenderers []DiagramRenderer
+	// Reformat valid json code blocks with an indentation of four spaces and
+	// mark invalid ones
+	PrettyJSON bool
+	// Render the added and removed lines of diff code blocks as <ins> and
+	// <del> instead of highlighting them
+	SemanticDiff bool
+	// Add a button copying the code to code blocks
+	CodeCopyButton bool
+	// Label of the copy button, "Copy" if empty
+	CodeCopyLabel string
+	// Limit the height of code blocks to this number of lines and scroll the
+	// rest, 0 disables the limit
+	CodeBlockMaxLines int
+	// Height of a line of code in pixels, used for CodeBlockMaxLines, 20
+	// if not positive
+	CodeBlockLineHeight int
+	// Add a button expanding code blocks limited by CodeBlockMaxLines
+	CodeBlockExpandable bool
+	// Render images with a title, which are alone in their paragraph, as figure
+	// with the title as caption
+	ImageFigure bool
+	// Load images and image emojis only when they are scrolled into view
+	LazyImages bool
+	// URL relative links and images are resolved against, e.g. the URL the
+	// document is published at
+	BaseURL *url.URL
+	// Open links to other sites than BaseURL in a new tab
+	ExternalLinksNewTab bool
+	// URL of the repository of the document, e.g.
+	// "https://github.com/owner/repo"
+	RepoURL string
+	// Link @username mentions to the profile of the user
+	GitHubMentions bool
+	// URL the username of mentions is appended to, defaults to the host of
+	// RepoURL, e.g. "https://github.com/"
+	MentionBaseURL string
+	// Link #123 to the issue of RepoURL
+	IssueReferences bool
+	// Path or URL wikilinks like [[Page Name]] are resolved against, e.g.
+	// "/wiki". Empty leaves them as text
+	WikiBase string
+	// Files rendered by NewHTTPHandler, defaults to the working directory
+	ServeFS fs.FS
+	// Serve the code highlighting stylesheet at /.grip/style.css from
+	// NewHTTPHandler instead of inlining it into every page
+	ServeCSS bool
+	// Remove all elements and attributes not allowed by a whitelist from the
+	// output, e.g. scripts and event handlers. This includes the scripts of
+	// mermaid diagrams and copy buttons
+	SanitizeHTML bool
+	// Pass HTML of the document through to the output. Disabled, it is left
+	// out, so that documents cannot embed scripts
+	RawHTMLEnabled bool
+	// Replace straight quotes with curly ones, "--" and "---" with en and em
+	// dashes and format fractions like 1/2
+	SmartTypography bool
+	// Parse footnotes like [^1] and render them at the end of the document
+	FootnotesEnabled bool
+	// Parse definition lists, a term followed by lines starting with ":"
+	DefinitionLists bool
+	// Class of the <del> elements of ~~strikethrough~~ text
+	StrikethroughClass string
+	// Render ==highlighted== text as <mark> elements
+	MarkHighlight bool
+	// Render ^superscript^ and ~subscript~ text as <sup> and <sub> elements
+	SubSuperscript bool
+	// Script rendering $math$ and $$math$$ in the browser, "mathjax" or
+	// "katex". Empty renders the math as text
+	MathEngine string
+	// Chroma style of the code highlighting CSS, one of AvailableThemes, e.g.
+	// "monokai". Empty uses "github" and "github-dark" according to the Theme
+	ChromaTheme string
+	// Theme of mermaid diagrams [default/dark/forest/neutral/base]. Empty
+	// derives it from the ChromaTheme or the Theme
+	MermaidTheme string
+	// Wrap abbreviations defined like "*[HTTP]: Hypertext Transfer Protocol"
+	// in <abbr> elements with the definition as title
+	Abbreviations bool
+	// Render pandoc fenced divs, "::: class" up to ":::", as <div> with the
+	// class. Divs with an alert type like "::: warning" are rendered as alerts
+	FencedDivs bool
+	// Render keyboard shortcuts like [[Ctrl+C]] as <kbd> elements. Ignored if
+	// WikiBase is set, as wikilinks use the same syntax
+	KeyboardShortcuts bool
+	// Render paragraphs starting with ">>> summary" as <details>, with the
+	// rest of the first line as summary
+	CollapsibleParagraphs bool
+	// Minify the output with tdewolff/minify, keeping the content of <pre> as
+	// it is
+	MinifyHTML bool
+	// Remove HTML comments of the document from the output
+	StripComments bool
+	// Keep conditional comments like <!--[if IE]> with StripComments
+	PreserveConditionalComments bool
+	// Replace emoji names like :smile: with emojis, never in code
+	EmojiEnabled bool
+	// Wrap tables in a <div class="table-responsive"> scrolling horizontally
+	ResponsiveTables bool
+	// Add the classes row-odd and row-even to the rows of table bodies
+	StripedTables bool
+	// Render a "Table: caption" paragraph in front of a table as its <caption>
+	TableCaptions bool
+}
+
+func DefaultParserOptions() ParserOptions {
+	return ParserOptions{
+		Theme:                    "auto",
+		LineNumbersStart:         1,
+		EmojiSize:                20,
+		WordsPerMinute:           200,
+		HeadingPermalinkIcon:     "¶",
+		HeadingPermalinkPosition: "before",
+		CodeCopyLabel:            "Copy",
+		CodeBlockLineHeight:      20,
+		LazyImages:               true,
+		EmojiEnabled:             true,
+	}
+}
+
+// Option modifies ParserOptions, it is applied by NewParser after the
+// passed options struct
+type Option func(*ParserOptions)
+
+func WithTheme(theme string) Option {
+	return func(o *ParserOptions) {
+		o.Theme = theme
+	}
+}
+
+func WithLineNumbers(lineNumbers bool) Option {
+	return func(o *ParserOptions) {
+		o.LineNumbers = lineNumbers
+	}
+}
+
+func WithLineNumbersStart(start int) Option {
+	return func(o *ParserOptions) {
+		o.LineNumbersStart = start
+	}
+}
+
+func WithExtraEmoji(emoji map[string]string) Option {
+	return func(o *ParserOptions) {
+		o.ExtraEmoji = emoji
+	}
+}
+
+func WithEmojiSize(size int) Option {
+	return func(o *ParserOptions) {
+		o.EmojiSize = size
+	}
+}
+
+func WithTaskListProgress(progress bool) Option {
+	return func(o *ParserOptions) {
+		o.TaskListProgress = progress
+	}
+}
+
+func WithAlertIcons(icons map[string]template.HTML) Option {
+	return func(o *ParserOptions) {
+		o.AlertIcons = icons
+	}
+}
+
+func WithAlertTitles(titles map[string]string) Option {
+	return func(o *ParserOptions) {
+		o.AlertTitles = titles
+	}
+}
+
+func WithExtraAlertTypes(types ...string) Option {
+	return func(o *ParserOptions) {
+		o.ExtraAlertTypes = types
+	}
+}
+
+func WithTemplateFS(fsys fs.FS) Option {
+	return func(o *ParserOptions) {
+		o.TemplateFS = fsys
+	}
+}
+
+func WithTOCMaxDepth(depth int) Option {
+	return func(o *ParserOptions) {
+		o.TOCMaxDepth = depth
+	}
+}
+
+func WithWordsPerMinute(wpm int) Option {
+	return func(o *ParserOptions) {
+		o.WordsPerMinute = wpm
+	}
+}
+
+func WithCountCodeWords(count bool) Option {
+	return func(o *ParserOptions) {
+		o.CountCodeWords = count
+	}
+}
+
+func WithParseFrontMatter(parse bool) Option {
+	return func(o *ParserOptions) {
+		o.ParseFrontMatter = parse
+	}
+}
+
+func WithHeadingIDPrefix(prefix string) Option {
+	return func(o *ParserOptions) {
+		o.HeadingIDPrefix = prefix
+	}
+}
+
+func WithHeadingPermalinks(permalinks bool) Option {
+	return func(o *ParserOptions) {
+		o.HeadingPermalinks = permalinks
+	}
+}
+
+func WithHeadingPermalinkIcon(icon string) Option {
+	return func(o *ParserOptions) {
+		o.HeadingPermalinkIcon = icon
+	}
+}
+
+func WithHeadingPermalinkPosition(position string) Option {
+	return func(o *ParserOptions) {
+		o.HeadingPermalinkPosition = position
+	}
+}
+
+func WithMermaidCDNURL(url string) Option {
+	return func(o *ParserOptions) {
+		o.MermaidCDNURL = url
+	}
+}
+
+func WithMermaidLocalJS(js string) Option {
+	return func(o *ParserOptions) {
+		o.MermaidLocalJS = js
+	}
+}
+
+func WithMermaidConfig(config map[string]any) Option {
+	return func(o *ParserOptions) {
+		o.MermaidConfig = config
+	}
+}
+
+func WithDiagramRenderers(renderers ...DiagramRenderer) Option {
+	return func(o *ParserOptions) {
+		o.DiagramRenderers = renderers
+	}
+}
+
+func WithPrettyJSON(pretty bool) Option {
+	return func(o *ParserOptions) {
+		o.PrettyJSON = pretty
+	}
+}
+
+func WithSemanticDiff(semantic bool) Option {
+	return func(o *ParserOptions) {
+		o.SemanticDiff = semantic
+	}
+}
+
+func WithCodeCopyButton(button bool) Option {
+	return func(o *ParserOptions) {
+		o.CodeCopyButton = button
+	}
+}
+
+func WithCodeCopyLabel(label string) Option {
+	return func(o *ParserOptions) {
+		o.CodeCopyLabel = label
+	}
+}
+
+func WithCodeBlockMaxLines(lines int) Option {
+	return func(o *ParserOptions) {
+		o.CodeBlockMaxLines = lines
+	}
+}
+
+func WithCodeBlockLineHeight(height int) Option {
+	return func(o *ParserOptions) {
+		o.CodeBlockLineHeight = height
+	}
+}
+
+func WithCodeBlockExpandable(expandable bool) Option {
+	return func(o *ParserOptions) {
+		o.CodeBlockExpandable = expandable
+	}
+}
+
+func WithImageFigure(figure bool) Option {
+	return func(o *ParserOptions) {
+		o.ImageFigure = figure
+	}
+}
+
+func WithLazyImages(lazy bool) Option {
+	return func(o *ParserOptions) {
+		o.LazyImages = lazy
+	}
+}
+
+func WithBaseURL(base *url.URL) Option {
+	return func(o *ParserOptions) {
+		o.BaseURL = base
+	}
+}
+
+func WithExternalLinksNewTab(newTab bool) Option {
+	return func(o *ParserOptions) {
+		o.ExternalLinksNewTab = newTab
+	}
+}
+
+func WithRepoURL(url string) Option {
+	return func(o *ParserOptions) {
+		o.RepoURL = url
+	}
+}
+
+func WithGitHubMentions(mentions bool) Option {
+	return func(o *ParserOptions) {
+		o.GitHubMentions = mentions
+	}
+}
+
+func WithMentionBaseURL(url string) Option {
+	return func(o *ParserOptions) {
+		o.MentionBaseURL = url
+	}
+}
+
+func WithIssueReferences(references bool) Option {
+	return func(o *ParserOptions) {
+		o.IssueReferences = references
+	}
+}
+
+func WithWikiBase(base string) Option {
+	return func(o *ParserOptions) {
+		o.WikiBase = base
+	}
+}
+
+func WithServeFS(fsys fs.FS) Option {
+	return func(o *ParserOptions) {
+		o.ServeFS = fsys
+	}
+}
+
+func WithServeCSS(serve bool) Option {
+	return func(o *ParserOptions) {
+		o.ServeCSS = serve
+	}
+}
+
+func WithSanitizeHTML(sanitize bool) Option {
+	return func(o *ParserOptions) {
+		o.SanitizeHTML = sanitize
+	}
+}
+
+func WithRawHTMLEnabled(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.RawHTMLEnabled = enabled
+	}
+}
+
+func WithSmartTypography(smart bool) Option {
+	return func(o *ParserOptions) {
+		o.SmartTypography = smart
+	}
+}
+
+func WithFootnotesEnabled(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.FootnotesEnabled = enabled
+	}
+}
+
+func WithDefinitionLists(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.DefinitionLists = enabled
+	}
+}
+
+func WithStrikethroughClass(class string) Option {
+	return func(o *ParserOptions) {
+		o.StrikethroughClass = class
+	}
+}
+
+func WithMarkHighlight(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.MarkHighlight = enabled
+	}
+}
+
+func WithSubSuperscript(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.SubSuperscript = enabled
+	}
+}
+
+func WithMathEngine(engine string) Option {
+	return func(o *ParserOptions) {
+		o.MathEngine = engine
+	}
+}
+
+func WithChromaTheme(theme string) Option {
+	return func(o *ParserOptions) {
+		o.ChromaTheme = theme
+	}
+}
+
+func WithMermaidTheme(theme string) Option {
+	return func(o *ParserOptions) {
+		o.MermaidTheme = theme
+	}
+}
+
+func WithAbbreviations(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.Abbreviations = enabled
+	}
+}
+
+func WithFencedDivs(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.FencedDivs = enabled
+	}
+}
+
+func WithKeyboardShortcuts(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.KeyboardShortcuts = enabled
+	}
+}
+
+func WithCollapsibleParagraphs(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.CollapsibleParagraphs = enabled
+	}
+}
+
+func WithMinifyHTML(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.MinifyHTML = enabled
+	}
+}
+
+func WithStripComments(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.StripComments = enabled
+	}
+}
+
+func WithPreserveConditionalComments(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.PreserveConditionalComments = enabled
+	}
+}
+
+func WithEmojiEnabled(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.EmojiEnabled = enabled
+	}
+}
+
+func WithResponsiveTables(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.ResponsiveTables = enabled
+	}
+}
+
+func WithStripedTables(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.StripedTables = enabled
+	}
+}
+
+func WithTableCaptions(enabled bool) Option {
+	return func(o *ParserOptions) {
+		o.TableCaptions = enabled
+	}
+}
@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io/fs"
+	"strings"
+
+	"github.com/chrishrb/go-grip/defaults"
+)
+
+type PageOptions struct {
+	// Content of the <title> element
+	Title string
+	// URL of a stylesheet linked in the head
+	Stylesheet string
+	// Class of the <body> element, e.g. "markdown-body"
+	BodyClass string
+	// Language of the document, e.g. "en". Empty uses the "lang" field of the
+	// front matter with ParseFrontMatter, and "en" without one
+	Lang string
+	// Additional elements of the head, inserted as HTML
+	ExtraHead template.HTML
+	// Inline the CSS of the code highlighting matching the Theme of the parser
+	InlineChromaCSS bool
+	// Add <meta name="generator"> with the Version of go-grip to the head
+	EmitGeneratorMeta bool
+}
+
+type pageData struct {
+	PageOptions
+	// Styles of the go-grip extensions, e.g. highlighted lines and diffs
+	GripCSS        template.CSS
+	ChromaCSSLight template.CSS
+	ChromaCSSDark  template.CSS
+	Content        template.HTML
+	Version        string
+	// "rtl" for languages written from right to left
+	Dir string
+}
+
+// Languages written from right to left
+var rtlLanguages = map[string]bool{
+	"ar": true, "arc": true, "ckb": true, "dv": true, "fa": true, "he": true, "ks": true, "ku": true,
+	"ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// pageLang returns the language of the page and its text direction
+func (m Parser) pageLang(input []byte, lang string) (string, string) {
+	if lang == "" && m.opts.ParseFrontMatter {
+		// Invalid front matter falls back to the default language
+		meta, _, _ := ParseFrontMatter(input)
+		lang, _ = meta["lang"].(string)
+	}
+	if lang == "" {
+		lang = "en"
+	}
+
+	primary, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	if rtlLanguages[primary] {
+		return lang, "rtl"
+	}
+	return lang, ""
+}
+
+// MdToHTMLPage renders the document as complete HTML page using the template
+// "templates/page.html"
+func (m Parser) MdToHTMLPage(input []byte, opts PageOptions) ([]byte, error) {
+	content, renderErr := m.MdToHTML(input)
+
+	data := pageData{PageOptions: opts, Content: template.HTML(content), Version: Version()}
+	data.Lang, data.Dir = m.pageLang(input, opts.Lang)
+	gripCSS, err := fs.ReadFile(defaults.StaticFiles, "static/css/go-grip.css")
+	if err != nil {
+		return nil, err
+	}
+	data.GripCSS = template.CSS(gripCSS)
+	if opts.InlineChromaCSS {
+		if data.ChromaCSSLight, data.ChromaCSSDark, err = m.chromaCSS(); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := m.parseTemplate("templates/page.html")
+	if err != nil {
+		return nil, err
+	}
+	var page bytes.Buffer
+	if err := tmpl.Execute(&page, data); err != nil {
+		return nil, err
+	}
+	if m.opts.MinifyHTML {
+		out, err := minifyHTML(page.Bytes())
+		return out, errors.Join(renderErr, err)
+	}
+	return page.Bytes(), renderErr
+}
+
+// chromaThemes returns the chroma styles of the code highlighting for light
+// and dark color schemes
+func (m Parser) chromaThemes() (light, dark string) {
+	if m.opts.ChromaTheme != "" {
+		return m.opts.ChromaTheme, m.opts.ChromaTheme
+	}
+	return "github", "github-dark"
+}
+
+// chromaCSS returns the CSS of the code highlighting for the theme of the
+// parser, the auto theme needs both
+func (m Parser) chromaCSS() (light, dark template.CSS, err error) {
+	var errs [2]error
+	var css [2]string
+	lightTheme, darkTheme := m.chromaThemes()
+	// A single chroma theme is used for both color schemes
+	if m.opts.ChromaTheme != "" || m.opts.Theme != "dark" {
+		css[0], errs[0] = m.ExtractCSS(lightTheme)
+	}
+	if m.opts.ChromaTheme == "" && m.opts.Theme != "light" {
+		css[1], errs[1] = m.ExtractCSS(darkTheme)
+	}
+	return template.CSS(css[0]), template.CSS(css[1]), errors.Join(errs[:]...)
+}
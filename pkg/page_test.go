@@ -0,0 +1,23 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMdToHTMLPageStyles(t *testing.T) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := p.MdToHTMLPage([]byte("# Title\n"), PageOptions{Title: "Test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The dimming of highlighted lines and the diff colours need go-grip.css
+	for _, rule := range []string{".highlight-lines .chroma .line:not(.hl)", ".semantic-diff ins"} {
+		if !strings.Contains(string(page), rule) {
+			t.Errorf("page lacks %q:\n%s", rule, page)
+		}
+	}
+}
@@ -2,280 +2,830 @@ package pkg
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
-	"path"
+	"maps"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/alecthomas/chroma/v2"
 	chroma_html "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
-	"github.com/chrishrb/go-grip/defaults"
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
 )
 
-var blockquotes = []string{"Note", "Tip", "Important", "Warning", "Caution", "BlockQuote"}
+var emojiRegex = regexp.MustCompile(`(:\S+:)`)
 
+var inlineCodeLangRegex = regexp.MustCompile(`^\{\.([\w+#-]+)\}`)
+
+var (
+	checkedTaskMarkers   = []string{"[x]", "[✓]"}
+	uncheckedTaskMarkers = []string{"[ ]", "[✗]"}
+)
+
+const (
+	defaultCodeBlockLineHeight = 20
+	defaultCodeCopyLabel       = "Copy"
+)
+
+// Parser renders markdown as HTML. It is immutable after construction and
+// safe for concurrent use.
 type Parser struct {
-	theme string
+	opts      ParserOptions
+	templates *sync.Map
+}
+
+func NewParser(opts ParserOptions, options ...Option) (*Parser, error) {
+	for _, option := range options {
+		option(&opts)
+	}
+	// Changes of the caller must not race with rendering
+	opts.ExtraEmoji = maps.Clone(opts.ExtraEmoji)
+	opts.AlertIcons = maps.Clone(opts.AlertIcons)
+	opts.AlertTitles = maps.Clone(opts.AlertTitles)
+	opts.ExtraAlertTypes = slices.Clone(opts.ExtraAlertTypes)
+	opts.MermaidConfig = maps.Clone(opts.MermaidConfig)
+	opts.DiagramRenderers = slices.Clone(opts.DiagramRenderers)
+	if opts.BaseURL != nil {
+		base := *opts.BaseURL
+		opts.BaseURL = &base
+	}
+	// styles.Get falls back silently, so a typo would go unnoticed
+	if _, ok := styles.Registry[opts.ChromaTheme]; opts.ChromaTheme != "" && !ok {
+		return nil, fmt.Errorf("unknown code theme %q", opts.ChromaTheme)
+	}
+	p := &Parser{
+		opts:      opts,
+		templates: &sync.Map{},
+	}
+
+	if opts.MermaidLocalJS != "" && opts.MermaidCDNURL != "" {
+		log.Println("Warning: both MermaidLocalJS and MermaidCDNURL are set, using MermaidLocalJS")
+	}
+	if _, ok := mathEngineTemplates[opts.MathEngine]; opts.MathEngine != "" && !ok {
+		log.Println("Warning: Unknown math engine", opts.MathEngine, ", math is rendered without a script")
+	}
+	if opts.KeyboardShortcuts && opts.WikiBase != "" {
+		log.Println("Warning: both KeyboardShortcuts and WikiBase are set, [[…]] is rendered as wikilink")
+	}
+	if opts.MermaidTheme != "" && !slices.Contains(mermaidThemes, opts.MermaidTheme) {
+		log.Println("Warning: Unknown mermaid theme", opts.MermaidTheme, ", deriving it from the theme")
+		p.opts.MermaidTheme = ""
+	}
+
+	if err := p.loadTemplates(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func NewParserWithDefaults(options ...Option) (*Parser, error) {
+	return NewParser(DefaultParserOptions(), options...)
+}
+
+func (m Parser) MdToHTML(bytes []byte) ([]byte, error) {
+	return m.MdToHTMLCtx(context.Background(), bytes)
+}
+
+// MdToHTMLCtx stops rendering once ctx is done and returns the HTML rendered
+// so far together with the context error
+func (m Parser) MdToHTMLCtx(ctx context.Context, input []byte) ([]byte, error) {
+	return m.render(ctx, input)
+}
+
+func (m Parser) MdToHTMLWriter(ctx context.Context, r io.Reader, w io.Writer) error {
+	// The markdown parser needs the whole document
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	out, err := m.MdToHTMLCtx(ctx, input)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	if _, writeErr := w.Write(out); writeErr != nil {
+		return writeErr
+	}
+	return err
 }
 
-func NewParser(theme string) *Parser {
-	return &Parser{
-		theme: theme,
+const extensions = parser.NoIntraEmphasis | parser.Tables | parser.FencedCode |
+	parser.Autolink | parser.Strikethrough | parser.SpaceHeadings | parser.HeadingIDs |
+	parser.BackslashLineBreak | parser.MathJax | parser.OrderedListStart |
+	parser.AutoHeadingIDs
+
+// extensions returns the parser extensions enabled by the options
+func (m Parser) extensions() parser.Extensions {
+	exts := extensions
+	if m.opts.FootnotesEnabled {
+		exts |= parser.Footnotes
+	}
+	if m.opts.DefinitionLists {
+		exts |= parser.DefinitionLists
 	}
+	return exts
 }
 
-func (m Parser) MdToHTML(bytes []byte) []byte {
-	extensions := parser.NoIntraEmphasis | parser.Tables | parser.FencedCode |
-		parser.Autolink | parser.Strikethrough | parser.SpaceHeadings | parser.HeadingIDs |
-		parser.BackslashLineBreak | parser.MathJax | parser.OrderedListStart |
-		parser.AutoHeadingIDs
-	p := parser.NewWithExtensions(extensions)
-	doc := p.Parse(bytes)
+func (m Parser) parse(input []byte) ast.Node {
+	// Parsers are single use, Parse panics when called again and the state
+	// cannot be reset, so they are not pooled
+	p := parser.NewWithExtensions(m.extensions())
+	// Front matter is no markdown
+	_, _, body := splitFrontMatter(input)
+	if m.opts.FencedDivs {
+		body = m.expandFencedDivs(body)
+	}
+	if m.opts.CollapsibleParagraphs {
+		body = expandCollapsibleParagraphs(body)
+	}
+	doc := p.Parse(separateBlockquotes(body))
+	applyHeadingAttributes(doc)
+	sanitizeHeadingIDs(doc)
+	if m.opts.Abbreviations {
+		expandAbbreviations(doc)
+	}
+	m.resolveURLs(doc)
+	m.setLinkAttributes(doc)
+	return doc
+}
 
+// Curly quotes, fractions and dashes, "--" for en and "---" for em dashes
+const smartypantsFlags = html.Smartypants | html.SmartypantsFractions | html.SmartypantsDashes |
+	html.SmartypantsLatexDashes
+
+// newRenderer returns a renderer for a single document. It keeps track of the
+// heading IDs and suffixes repeated ones with -1, -2, … like GitHub, so it must
+// not be shared between renders.
+func (m Parser) newRenderer(hook html.RenderNodeFunc) *html.Renderer {
 	htmlFlags := html.CommonFlags
-	opts := html.RendererOptions{Flags: htmlFlags, RenderNodeHook: m.renderHook}
-	renderer := html.NewRenderer(opts)
+	if m.opts.LazyImages {
+		htmlFlags |= html.LazyLoadImages
+	}
+	if !m.opts.RawHTMLEnabled {
+		htmlFlags |= html.SkipHTML
+	}
+	if m.opts.SmartTypography {
+		htmlFlags |= smartypantsFlags
+	}
+	opts := html.RendererOptions{
+		Flags:           htmlFlags,
+		RenderNodeHook:  hook,
+		HeadingIDPrefix: m.opts.HeadingIDPrefix,
+	}
+	return html.NewRenderer(opts)
+}
+
+func (m Parser) render(ctx context.Context, input []byte) ([]byte, error) {
+	return m.renderDoc(ctx, m.parse(input))
+}
+
+func (m Parser) renderDoc(ctx context.Context, doc ast.Node) ([]byte, error) {
+	// Errors of the render hooks are collected, so that the partial HTML can
+	// still be returned to the caller
+	state := &renderState{ctx: ctx, parser: m}
+	renderer := m.newRenderer(state.renderHook)
+	state.renderer = renderer
+	if m.opts.SmartTypography {
+		state.smartypants = html.NewSmartypantsRenderer(smartypantsFlags)
+	}
 
-	return markdown.Render(doc, renderer)
+	out := markdown.Render(doc, renderer)
+	if m.opts.SanitizeHTML {
+		out = sanitizeHTML(out)
+	}
+	if m.opts.StripComments {
+		out = stripComments(out, m.opts.PreserveConditionalComments)
+	}
+	if m.opts.MinifyHTML {
+		var err error
+		if out, err = minifyHTML(out); err != nil {
+			state.errs = append(state.errs, err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		state.errs = append(state.errs, err)
+	}
+	return out, errors.Join(state.errs...)
 }
 
-func (m Parser) renderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
-	switch node.(type) {
+// AvailableThemes returns the names of the chroma styles usable as ChromaTheme
+func AvailableThemes() []string {
+	return slices.Sorted(maps.Keys(styles.Registry))
+}
+
+func (m Parser) ExtractCSS(theme string) (string, error) {
+	style, ok := styles.Registry[theme]
+	if !ok {
+		return "", fmt.Errorf("unknown code theme %q", theme)
+	}
+
+	buf := new(strings.Builder)
+	formatter := chroma_html.New(chroma_html.WithClasses(true))
+	if err := formatter.WriteCSS(buf, style); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderState holds the state of a single render call
+type renderState struct {
+	ctx      context.Context
+	parser   Parser
+	renderer *html.Renderer
+	// Smart typography keeps track of open quotes across texts
+	smartypants *html.SPRenderer
+	// Number of mermaid diagrams and of code blocks with a copy button, their
+	// IDs are unique within the document
+	mermaids   int
+	codeBlocks int
+	errs       []error
+}
+
+func (r *renderState) renderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	if r.ctx.Err() != nil {
+		return ast.Terminate, true
+	}
+
+	var status ast.WalkStatus
+	var handled bool
+	var err error
+	// These hooks extend the default rendering of the renderer
+	switch node := node.(type) {
+	case *ast.Document:
+		status, handled, err = r.renderMathScript(w, node, entering)
+	case *ast.Heading:
+		status, handled, err = r.renderHookHeading(w, node, entering)
+	case *ast.Image:
+		status, handled, err = r.renderHookImage(w, node, entering)
+	case *ast.Text:
+		status, handled, err = r.renderHookText(w, node)
+	case *ast.Math, *ast.MathBlock:
+		status, handled, err = r.renderHookMath(w, node, entering)
+	case *ast.CodeBlock:
+		status, handled, err = r.renderHookCodeBlock(w, node)
+	default:
+		status, handled, err = r.parser.renderHook(w, node, entering)
+	}
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+	return status, handled
+}
+
+// renderHookText applies the smart typography to the rendered text, after
+// emoji and links are inserted as HTML
+func (r *renderState) renderHookText(w io.Writer, node ast.Node) (ast.WalkStatus, bool, error) {
+	if r.smartypants == nil {
+		return r.parser.renderHookText(w, node)
+	}
+
+	var buf, out bytes.Buffer
+	status, handled, err := r.parser.renderHookText(&buf, node)
+	// Process drops the errors of the writer
+	r.smartypants.Process(&out, buf.Bytes())
+	if _, writeErr := w.Write(out.Bytes()); writeErr != nil {
+		err = writeErr
+	}
+	return status, handled, err
+}
+
+func (m Parser) renderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool, error) {
+	switch node := node.(type) {
+	case *ast.Link:
+		if node.NoteID != 0 {
+			return renderHookFootnoteRef(w, node, entering)
+		}
 	case *ast.BlockQuote:
-		return renderHookBlockQuote()
+		return m.renderHookBlockQuote(w, node, entering)
+	case *ast.HTMLBlock:
+		if isBlockquoteSeparator(node) {
+			return ast.GoToNext, true, nil
+		}
 	case *ast.Paragraph:
-		return renderHookParagraph(w, node, entering)
+		if m.isTableCaption(node) {
+			return ast.SkipChildren, true, nil
+		}
+		return m.renderHookParagraph(w, node, entering)
 	case *ast.Text:
-		return renderHookText(w, node)
+		return m.renderHookText(w, node)
+	case *ast.List:
+		if node.IsFootnotesList {
+			return renderHookFootnoteList(w, entering)
+		}
+		return m.renderHookList(w, node, entering)
 	case *ast.ListItem:
+		if node.RefLink != nil {
+			return renderHookFootnoteItem(w, node, entering)
+		}
 		return renderHookListItem(w, node, entering)
-	case *ast.CodeBlock:
-		return renderHookCodeBlock(w, node, m.theme)
+	case *ast.Code:
+		return renderHookCode(w, node)
+	case *ast.Del:
+		return m.renderHookDel(w, entering)
+	case *abbreviation:
+		return renderHookAbbreviation(w, node)
+	case *ast.Table:
+		return m.renderHookTable(w, node, entering)
+	case *ast.TableRow:
+		return m.renderHookTableRow(w, node, entering)
 	}
 
-	return ast.GoToNext, false
+	return ast.GoToNext, false, nil
 }
 
-func renderHookCodeBlock(w io.Writer, node ast.Node, theme string) (ast.WalkStatus, bool) {
+func (r *renderState) renderHookCodeBlock(w io.Writer, node ast.Node) (ast.WalkStatus, bool, error) {
+	m := r.parser
 	block := node.(*ast.CodeBlock)
+	info := parseFenceInfo(block.Info)
 
-	if string(block.Info) == "mermaid" {
-		m, err := renderMermaid(string(block.Literal), theme)
+	if diagram, found, err := r.renderDiagram(info.lang, string(block.Literal)); found {
+		if _, writeErr := io.WriteString(w, diagram); writeErr != nil {
+			err = writeErr
+		}
+		return ast.GoToNext, true, err
+	}
+
+	if info.lang == "csv" || info.lang == "tsv" {
+		table, err := renderCSV(info, string(block.Literal))
 		if err != nil {
-			log.Println("Error:", err)
+			table = sourceFallback(info.lang, string(block.Literal))
+		}
+		if _, writeErr := io.WriteString(w, table); writeErr != nil {
+			err = writeErr
 		}
-		fmt.Fprint(w, m)
-		return ast.GoToNext, true
+		return ast.GoToNext, true, err
 	}
 
-	var lexer chroma.Lexer
-	if block.Info == nil {
-		lexer = lexers.Analyse(string(block.Literal))
+	if title, ok := info.attrs["title"]; ok {
+		_, err := fmt.Fprintf(w, `<div class="code-title"><span>%s</span></div>`, template.HTMLEscapeString(title))
+		if err != nil {
+			return ast.GoToNext, true, err
+		}
+	}
+
+	lexer, name, detected := codeLexer(info.lang, string(block.Literal))
+	label := "code block"
+	if detected {
+		label = fmt.Sprintf("code block (detected: %s)", name)
+	} else if name != "" {
+		label = name + " code block"
+	}
+	_, err := fmt.Fprintf(w, `<div role="region" aria-label="%s">`, template.HTMLEscapeString(label))
+	if err != nil {
+		return ast.GoToNext, true, err
+	}
+
+	if m.opts.CodeCopyButton {
+		r.codeBlocks++
+		id := r.codeBlocks
+		label := m.opts.CodeCopyLabel
+		if label == "" {
+			label = defaultCodeCopyLabel
+		}
+		_, err := fmt.Fprintf(w, `<div class="code-block-wrapper"><button class="copy-btn" data-clipboard-target="#code-block-%d code" `+
+			`onclick="navigator.clipboard.writeText(document.querySelector(this.dataset.clipboardTarget).innerText)">%s</button><div id="code-block-%d">`,
+			id, template.HTMLEscapeString(label), id)
+		if err != nil {
+			return ast.GoToNext, true, err
+		}
+	}
+
+	// Long code blocks scroll instead of taking up the whole page
+	lineCount := strings.Count(strings.TrimSuffix(string(block.Literal), "\n"), "\n") + 1
+	lineHeight := m.opts.CodeBlockLineHeight
+	if lineHeight <= 0 {
+		lineHeight = defaultCodeBlockLineHeight
+	}
+	height := m.opts.CodeBlockMaxLines * lineHeight
+	scroll := m.opts.CodeBlockMaxLines > 0 && lineCount > m.opts.CodeBlockMaxLines
+	if scroll {
+		_, err := fmt.Fprintf(w, `<div class="code-block-scroll" style="max-height: %dpx; overflow-y: auto;">`, height)
+		if err != nil {
+			return ast.GoToNext, true, err
+		}
+	}
+
+	if err := m.writeCode(w, info, lexer, string(block.Literal)); err != nil {
+		return ast.GoToNext, true, err
+	}
+
+	if scroll {
+		end := "</div>"
+		if m.opts.CodeBlockExpandable {
+			end += fmt.Sprintf(`<button class="code-expand-btn" onclick="const d = this.previousElementSibling; `+
+				`const expand = d.style.maxHeight !== 'none'; d.style.maxHeight = expand ? 'none' : '%dpx'; `+
+				`this.textContent = expand ? 'Collapse' : 'Expand'">Expand</button>`, height)
+		}
+		if _, err := io.WriteString(w, end); err != nil {
+			return ast.GoToNext, true, err
+		}
+	}
+
+	end := "</div>"
+	if m.opts.CodeCopyButton {
+		end = "</div></div>" + end
+	}
+	_, err = io.WriteString(w, end)
+	return ast.GoToNext, true, err
+}
+
+// codeLexer returns the lexer for the language of a code block and the name
+// of the language. Without a language, it is detected from the code.
+func codeLexer(lang, code string) (lexer chroma.Lexer, name string, detected bool) {
+	if lang == "" {
+		lexer = lexers.Analyse(code)
+		detected = lexer != nil
 	} else {
-		lexer = lexers.Get(string(block.Info))
+		lexer = lexers.Get(lang)
+		name = lang
 	}
 	// ensure lexer is never nil
 	if lexer == nil {
-		lexer = lexers.Get("plaintext")
+		return lexers.Get("plaintext"), name, false
 	}
+	return lexer, lexer.Config().Name, detected
+}
 
-	iterator, _ := lexer.Tokenise(nil, string(block.Literal))
-	formatter := chroma_html.New(chroma_html.WithClasses(true))
-	err := formatter.Format(w, styles.Fallback, iterator)
+// writeCode writes the highlighted code of a code block
+func (m Parser) writeCode(w io.Writer, info fenceInfo, lexer chroma.Lexer, code string) error {
+	if m.opts.SemanticDiff && info.lang == "diff" {
+		_, err := io.WriteString(w, renderSemanticDiff(code))
+		return err
+	}
+
+	if m.opts.PrettyJSON && info.lang == "json" {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, bytes.TrimSpace([]byte(code)), "", "    "); err == nil {
+			code = pretty.String() + "\n"
+		} else if _, err := io.WriteString(w, `<span class="json-error">invalid JSON</span>`); err != nil {
+			return err
+		}
+	}
+
+	iterator, err := lexer.Tokenise(nil, code)
 	if err != nil {
-		log.Println("Error:", err)
+		return err
+	}
+
+	// A per-block theme is rendered with inline styles, as the page wide
+	// stylesheet only covers the document theme
+	style := styles.Fallback
+	options := []chroma_html.Option{chroma_html.WithClasses(true)}
+	if name, ok := info.attrs["theme"]; ok {
+		if s, found := styles.Registry[name]; found {
+			style = s
+			options = []chroma_html.Option{chroma_html.WithClasses(false)}
+		} else {
+			log.Println("Warning: Unknown code theme", name, ", using document theme")
+		}
+	}
+
+	lineNumbers, lineNumbersStart := m.opts.LineNumbers, m.opts.LineNumbersStart
+	if v, ok := info.attrs["linestart"]; ok {
+		start, err := strconv.Atoi(v)
+		if err != nil {
+			log.Println("Warning: invalid linestart", v)
+		} else {
+			lineNumbers, lineNumbersStart = true, start
+		}
+	}
+	if lineNumbersStart < 1 {
+		lineNumbersStart = 1
+	}
+	if lineNumbers {
+		options = append(options, chroma_html.WithLineNumbers(true), chroma_html.LineNumbersInTable(true),
+			chroma_html.BaseLineNumber(lineNumbersStart))
+	}
+
+	var highlight [][2]int
+	if info.highlight != "" {
+		lineCount := strings.Count(strings.TrimSuffix(code, "\n"), "\n") + 1
+		highlight = parseLineRanges(info.highlight, lineCount)
+	}
+	if len(highlight) > 0 {
+		// chroma numbers lines starting at the base line number
+		offset := 0
+		if lineNumbers {
+			offset = lineNumbersStart - 1
+		}
+		for i := range highlight {
+			highlight[i][0] += offset
+			highlight[i][1] += offset
+		}
+		options = append(options, chroma_html.HighlightLines(highlight))
+		if _, err := io.WriteString(w, `<div class="highlight-lines">`); err != nil {
+			return err
+		}
+	}
+
+	formatter := chroma_html.New(options...)
+	if err := formatter.Format(w, style, iterator); err != nil {
+		return err
 	}
-	return ast.GoToNext, true
+
+	if len(highlight) > 0 {
+		_, err = io.WriteString(w, "</div>")
+	}
+	return err
 }
 
-func renderHookBlockQuote() (ast.WalkStatus, bool) {
-	return ast.GoToNext, true
+// renderHookCode highlights inline code followed by a language hint like
+// `x := 1`{.go}
+func renderHookCode(w io.Writer, node ast.Node) (ast.WalkStatus, bool, error) {
+	next, ok := ast.GetNextNode(node).(*ast.Text)
+	if !ok {
+		return ast.GoToNext, false, nil
+	}
+	match := inlineCodeLangRegex.FindSubmatch(next.Literal)
+	if match == nil {
+		return ast.GoToNext, false, nil
+	}
+	lexer := lexers.Get(string(match[1]))
+	if lexer == nil {
+		return ast.GoToNext, false, nil
+	}
+	// The hint is not part of the following text
+	next.Literal = next.Literal[len(match[0]):]
+
+	iterator, err := lexer.Tokenise(nil, string(node.AsLeaf().Literal))
+	if err != nil {
+		return ast.GoToNext, true, err
+	}
+	if _, err := fmt.Fprintf(w, `<code class="chroma language-%s">`, template.HTMLEscapeString(string(match[1]))); err != nil {
+		return ast.GoToNext, true, err
+	}
+	formatter := chroma_html.New(chroma_html.WithClasses(true), chroma_html.PreventSurroundingPre(true))
+	if err := formatter.Format(w, styles.Fallback, iterator); err != nil {
+		return ast.GoToNext, true, err
+	}
+	_, err = io.WriteString(w, "</code>")
+	return ast.GoToNext, true, err
 }
 
-func renderHookParagraph(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+func (m Parser) renderHookParagraph(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool, error) {
 	paragraph := node.(*ast.Paragraph)
 
-	_, ok := paragraph.GetParent().(*ast.BlockQuote)
-	if !ok {
-		return ast.GoToNext, false
+	// A <figure> must not be nested in a <p>
+	if m.isFigure(paragraph) {
+		return ast.GoToNext, true, nil
 	}
 
-	t, ok := (paragraph.GetChildren()[0]).(*ast.Text)
+	if len(paragraph.GetChildren()) != 1 {
+		return ast.GoToNext, false, nil
+	}
+
+	t, ok := paragraph.GetChildren()[0].(*ast.Text)
 	if !ok {
-		return ast.GoToNext, false
+		return ast.GoToNext, false, nil
 	}
 
-	// Get the text content of the blockquote
-	content := string(t.Literal)
+	// Drop paragraphs only consisting of the alert marker
+	if content, found := m.cutAlertMarker(t); found && content == "" {
+		return ast.SkipChildren, true, nil
+	}
 
-	var alert string
-	for _, b := range blockquotes {
-		if strings.HasPrefix(content, fmt.Sprintf("[!%s]", strings.ToUpper(b))) {
-			alert = strings.ToLower(b)
-		}
+	// The attribution of a quote is rendered by its blockquote
+	if m.quoteAttribution(paragraph.GetParent()) == paragraph {
+		return ast.SkipChildren, true, nil
 	}
 
-	if alert == "" {
-		return ast.GoToNext, false
+	return ast.GoToNext, false, nil
+}
+
+func (m Parser) renderHookText(w io.Writer, node ast.Node) (ast.WalkStatus, bool, error) {
+	block := node.(*ast.Text)
+
+	literal := string(block.Literal)
+	if content, found := m.cutAlertMarker(block); found {
+		literal = content
 	}
+	withEmoji := replaceText(literal, m.textReplacers(block))
 
-	// Set the message type based on the content of the blockquote
-	var err error
-	if entering {
-		var s string
-		s, _ = createBlockquoteStart(alert)
-		_, err = io.WriteString(w, s)
-	} else {
-		_, err = io.WriteString(w, "</div>")
+	paragraph, ok := block.GetParent().(*ast.Paragraph)
+	if !ok {
+		_, err := io.WriteString(w, withEmoji)
+		return ast.GoToNext, true, err
 	}
-	if err != nil {
-		log.Println("Error:", err)
+
+	item, ok := paragraph.GetParent().(*ast.ListItem)
+	if ok && item.ListFlags&ast.ListTypeDefinition == 0 {
+		content, checked, found := cutTaskMarker(withEmoji)
+		if found {
+			if checked {
+				content = `<input type="checkbox" disabled class="task-list-item-checkbox" checked> ` + content
+			} else {
+				content = `<input type="checkbox" disabled class="task-list-item-checkbox"> ` + content
+			}
+			_, err := io.WriteString(w, content)
+			return ast.GoToNext, true, err
+		}
 	}
 
-	return ast.GoToNext, true
+	_, err := io.WriteString(w, withEmoji)
+	return ast.GoToNext, true, err
 }
 
-func renderHookText(w io.Writer, node ast.Node) (ast.WalkStatus, bool) {
-	block := node.(*ast.Text)
-
-	r := regexp.MustCompile(`(:\S+:)`)
-	withEmoji := r.ReplaceAllStringFunc(string(block.Literal), func(s string) string {
-		val, ok := EmojiMap[s]
+func (m Parser) replaceEmoji(text string) string {
+	return emojiRegex.ReplaceAllStringFunc(text, func(s string) string {
+		// Emojis of the parser take precedence over the built-in ones
+		val, ok := m.opts.ExtraEmoji[s]
+		if !ok {
+			val, ok = EmojiMap[s]
+		}
 		if !ok {
-			return s
+			return escapeText(s)
 		}
 
-		if strings.HasPrefix(val, "/") {
-			return fmt.Sprintf(`<img class="emoji" title="%s" alt="%s" src="%s" height="20" width="20" align="absmiddle">`, s, s, val)
+		if strings.HasPrefix(val, "/") || strings.HasPrefix(val, "http") {
+			var attrs string
+			if m.opts.EmojiSize > 0 {
+				attrs = fmt.Sprintf(` height="%d" width="%d"`, m.opts.EmojiSize, m.opts.EmojiSize)
+			}
+			if m.opts.LazyImages {
+				attrs += ` loading="lazy"`
+			}
+			return fmt.Sprintf(`<img class="emoji" title="%s" alt="%s" src="%s"%s align="absmiddle">`,
+				template.HTMLEscapeString(s), template.HTMLEscapeString(s), template.HTMLEscapeString(val), attrs)
 		}
 
 		return val
 	})
+}
 
-	paragraph, ok := block.GetParent().(*ast.Paragraph)
-	if !ok {
-		_, err := io.WriteString(w, withEmoji)
-		if err != nil {
-			log.Println("Error:", err)
-		}
-		return ast.GoToNext, true
-	}
-
-	_, ok = paragraph.GetParent().(*ast.BlockQuote)
-	if ok {
-		// Remove prefixes
-		for _, b := range blockquotes {
-			content, found := strings.CutPrefix(withEmoji, fmt.Sprintf("[!%s]", strings.ToUpper(b)))
-			if found {
-				_, err := io.WriteString(w, content)
-				if err != nil {
-					log.Println("Error:", err)
+func (m Parser) renderHookList(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool, error) {
+	// Nested lists are counted by the outermost list
+	if _, nested := node.GetParent().(*ast.ListItem); !entering || nested || !m.opts.TaskListProgress {
+		return ast.GoToNext, false, nil
+	}
+
+	var done, total int
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if item, ok := n.(*ast.ListItem); ok && entering {
+			if checked, found := taskListItem(item); found {
+				total++
+				if checked {
+					done++
 				}
-				return ast.GoToNext, true
 			}
 		}
+		return ast.GoToNext
+	})
+
+	if total == 0 {
+		return ast.GoToNext, false, nil
 	}
 
-	_, ok = paragraph.GetParent().(*ast.ListItem)
-	if ok {
-		content, found := strings.CutPrefix(withEmoji, "[ ]")
-		content = `<input type="checkbox" disabled class="task-list-item-checkbox"> ` + content
-		if found {
-			_, err := io.WriteString(w, content)
-			if err != nil {
-				log.Println("Error:", err)
-			}
-			return ast.GoToNext, true
-		}
+	_, err := fmt.Fprintf(w, `<progress class="task-list-progress" value="%d" max="%d"><span>%d/%d tasks</span></progress>`,
+		done, total, done, total)
+	return ast.GoToNext, false, err
+}
 
-		content, found = strings.CutPrefix(withEmoji, "[x]")
-		content = `<input type="checkbox" disabled class="task-list-item-checkbox" checked> ` + content
-		if found {
-			_, err := io.WriteString(w, content)
-			if err != nil {
-				log.Println("Error:", err)
-			}
-		}
+// renderHookDel adds the StrikethroughClass to strikethrough text
+func (m Parser) renderHookDel(w io.Writer, entering bool) (ast.WalkStatus, bool, error) {
+	if m.opts.StrikethroughClass == "" {
+		return ast.GoToNext, false, nil
 	}
 
-	_, err := io.WriteString(w, withEmoji)
-	if err != nil {
-		log.Println("Error:", err)
+	s := "</del>"
+	if entering {
+		s = `<del class="` + template.HTMLEscapeString(m.opts.StrikethroughClass) + `">`
 	}
-	return ast.GoToNext, true
+	_, err := io.WriteString(w, s)
+	return ast.GoToNext, true, err
 }
 
-func renderHookListItem(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
-	block := node.(*ast.ListItem)
+func renderHookListItem(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool, error) {
+	checked, found := taskListItem(node.(*ast.ListItem))
+	if !found {
+		return ast.GoToNext, false, nil
+	}
 
-	paragraph, ok := (block.GetChildren()[0]).(*ast.Paragraph)
-	if !ok {
-		return ast.GoToNext, false
+	var err error
+	if entering && checked {
+		_, err = io.WriteString(w, "<li class=\"task-list-item task-list-item--checked\">")
+	} else if entering {
+		_, err = io.WriteString(w, "<li class=\"task-list-item\">")
+	} else {
+		_, err = io.WriteString(w, "</li>")
 	}
 
-	t, ok := (paragraph.GetChildren()[0]).(*ast.Text)
-	if !ok {
-		return ast.GoToNext, false
+	return ast.GoToNext, true, err
+}
+
+// taskListItem reports whether item starts with a task list marker and
+// whether the task is checked
+func taskListItem(item *ast.ListItem) (bool, bool) {
+	children := item.GetChildren()
+	// Terms and definitions are no tasks
+	if len(children) == 0 || item.ListFlags&ast.ListTypeDefinition != 0 {
+		return false, false
 	}
 
-	if !(strings.HasPrefix(string(t.Literal), "[ ]") || strings.HasPrefix(string(t.Literal), "[x]")) {
-		return ast.GoToNext, false
+	paragraph, ok := children[0].(*ast.Paragraph)
+	if !ok || len(paragraph.GetChildren()) == 0 {
+		return false, false
 	}
 
-	if entering {
-		_, err := io.WriteString(w, "<li class=\"task-list-item\">")
-		if err != nil {
-			log.Println("Error:", err)
-		}
-	} else {
-		_, err := io.WriteString(w, "</li>")
-		if err != nil {
-			log.Println("Error:", err)
-		}
+	t, ok := (paragraph.GetChildren()[0]).(*ast.Text)
+	if !ok {
+		return false, false
 	}
 
-	return ast.GoToNext, true
+	_, checked, found := cutTaskMarker(string(t.Literal))
+	return checked, found
 }
 
-func createBlockquoteStart(alert string) (string, error) {
-	lp := path.Join("templates/alert", fmt.Sprintf("%s.html", alert))
-	tmpl, err := template.ParseFS(defaults.Templates, lp)
-	if err != nil {
-		return "", err
+// cutTaskMarker removes a leading task list marker from s and reports whether
+// the task is checked. "[x]", "[X]" and "[✓]" mark checked tasks, "[ ]" and
+// "[✗]" unchecked ones.
+func cutTaskMarker(s string) (string, bool, bool) {
+	for _, marker := range uncheckedTaskMarkers {
+		if content, found := strings.CutPrefix(s, marker); found {
+			return content, false, true
+		}
 	}
-	var tpl bytes.Buffer
-	if err := tmpl.Execute(&tpl, alert); err != nil {
-		return "", err
+
+	for _, marker := range checkedTaskMarkers {
+		if len(s) >= len(marker) && strings.EqualFold(s[:len(marker)], marker) {
+			return s[len(marker):], true, true
+		}
 	}
-	return tpl.String(), nil
+
+	return s, false, false
 }
 
+// Numbers the diagrams of all renders, so that they stay unique when the
+// output of several renders ends up on one page
 type mermaid struct {
+	ID      string
 	Content string
 	Theme   string
+	CDNURL  string
+	LocalJS template.URL
+	Config  template.JS
 }
 
-func renderMermaid(content string, theme string) (string, error) {
-	m := mermaid{
+var mermaidThemes = []string{"default", "dark", "forest", "neutral", "base"}
+
+// mermaidTheme returns the theme of mermaid diagrams, empty if it depends on
+// the color scheme of the browser
+func (m Parser) mermaidTheme() string {
+	switch {
+	case m.opts.MermaidTheme != "":
+		return m.opts.MermaidTheme
+	case m.opts.ChromaTheme != "":
+		// Dark code themes have a dark background
+		background := styles.Get(m.opts.ChromaTheme).Get(chroma.Background).Background
+		if background.IsSet() && background.Brightness() < 0.5 {
+			return "dark"
+		}
+		return "default"
+	case m.opts.Theme == "dark":
+		return "dark"
+	case m.opts.Theme == "light":
+		return "default"
+	}
+	return ""
+}
+
+func (r *renderState) renderMermaid(content string) (string, error) {
+	m := r.parser
+	r.mermaids++
+	data := mermaid{
+		ID:      strconv.Itoa(r.mermaids),
 		Content: content,
-		Theme:   theme,
+		Theme:   m.mermaidTheme(),
+		CDNURL:  m.opts.MermaidCDNURL,
+		// Set by the user, so data: URIs are allowed
+		LocalJS: template.URL(m.opts.MermaidLocalJS),
+	}
+	if len(m.opts.MermaidConfig) > 0 {
+		config, err := json.Marshal(m.opts.MermaidConfig)
+		if err != nil {
+			return "", err
+		}
+		data.Config = template.JS(config)
 	}
-	lp := path.Join("templates/mermaid/mermaid.html")
-	tmpl, err := template.ParseFS(defaults.Templates, lp)
+	tmpl, err := m.parseTemplate("templates/mermaid/mermaid.html")
 	if err != nil {
 		return "", err
 	}
 	var tpl bytes.Buffer
-	if err := tmpl.Execute(&tpl, m); err != nil {
+	if err := tmpl.Execute(&tpl, data); err != nil {
 		return "", err
 	}
 	return tpl.String(), nil
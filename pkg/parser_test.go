@@ -0,0 +1,325 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRawHTMLDisabled(t *testing.T) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []string{
+		"<script>alert(1)</script>\n",
+		"text <script>alert(1)</script> text\n",
+		"\\<script>alert(1)\\</script>\n",
+		"&#60;script&#62;alert(1)&#60;/script&#62;\n",
+		"==<script>alert(1)</script>==\n",
+		":<script>: @x<script>\n",
+		"- [ ] \\<script>alert(1)\\</script>\n",
+	}
+	for _, input := range inputs {
+		out, err := p.MdToHTML([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(strings.ToLower(string(out)), "<script") {
+			t.Errorf("%q rendered a script:\n%s", input, out)
+		}
+	}
+}
+
+func TestEmoji(t *testing.T) {
+	// The default options replace emoji names
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := p.MdToHTML([]byte(":smile:\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), ":smile:") {
+		t.Errorf("emoji not replaced:\n%s", out)
+	}
+
+	p, err = NewParserWithDefaults(WithEmojiEnabled(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = p.MdToHTML([]byte(":smile:\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), ":smile:") {
+		t.Errorf("emoji replaced with EmojiEnabled disabled:\n%s", out)
+	}
+}
+
+func TestCodeBlockLineHeight(t *testing.T) {
+	p, err := NewParserWithDefaults(WithCodeBlockMaxLines(2), WithCodeBlockLineHeight(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := p.MdToHTML([]byte("```\n1\n2\n3\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "max-height: 40px") {
+		t.Errorf("want the default line height of 20px:\n%s", out)
+	}
+}
+
+func TestCodeCopyLabel(t *testing.T) {
+	p, err := NewParserWithDefaults(WithCodeCopyButton(true), WithCodeCopyLabel(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := p.MdToHTML([]byte("```\ncode\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), ">Copy</button>") {
+		t.Errorf("want the default label:\n%s", out)
+	}
+	// The ids of the code blocks are numbered per render
+	if !strings.Contains(string(out), `id="code-block-1"`) {
+		t.Errorf("want the id of the first code block:\n%s", out)
+	}
+}
+
+func FuzzMdToHTML(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add([]byte("> [!NOTE]\n"))
+	f.Add([]byte("```go\nfunc main() {\n"))
+	f.Add([]byte(strings.Repeat(">", 100) + " deep\n"))
+	f.Add([]byte(":smile: :+1: :rocket:\n"))
+
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		// Errors are fine, panics are not
+		_, _ = p.MdToHTML(input)
+	})
+}
+
+func TestMdToHTMLConcurrent(t *testing.T) {
+	extraEmoji := map[string]string{":logo:": "/logo.svg"}
+	p, err := NewParserWithDefaults(
+		WithExtraEmoji(extraEmoji),
+		WithHeadingPermalinks(true),
+		WithCodeCopyButton(true),
+		WithLineNumbers(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The parser keeps a copy of the options
+	extraEmoji[":other:"] = "/other.svg"
+
+	input := []byte("# Title\n\n> [!NOTE]\n> :logo: :smile:\n\n```go\nfunc main() {}\n```\n\n" +
+		"```mermaid\ngraph LR\n  A --> B\n```\n\n| a | b |\n|---|---|\n| 1 | 2 |\n")
+	want, err := p.MdToHTML(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				out, err := p.MdToHTML(input)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if string(out) != string(want) {
+					t.Errorf("concurrent render differs:\n%s\nwant:\n%s", out, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMermaidIDs(t *testing.T) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []byte("```mermaid\ngraph LR\n  A --> B\n```\n\n```mermaid\ngraph LR\n  B --> C\n```\n")
+	first, err := p.MdToHTML(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{`id="mermaid-1"`, `id="mermaid-2"`} {
+		if !strings.Contains(string(first), id) {
+			t.Errorf("want %s:\n%s", id, first)
+		}
+	}
+
+	// The output does not depend on earlier renders
+	second, err := p.MdToHTML(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("renders differ:\n%s\n%s", first, second)
+	}
+}
+
+func TestDefinitionLists(t *testing.T) {
+	p, err := NewParserWithDefaults(WithDefinitionLists(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "term and definition",
+			input: "Term\n: Definition\n",
+			want:  "<dl>\n<dt>Term</dt>\n<dd>Definition</dd>\n</dl>",
+		},
+		{
+			// Terms are no tasks
+			name:  "task marker",
+			input: "[x] Term\n: Definition\n",
+			want:  "<dl>\n<dt>[x] Term</dt>\n<dd>Definition</dd>\n</dl>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.MdToHTML([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("output lacks %q:\n%s", tt.want, out)
+			}
+			if strings.Contains(string(out), "checkbox") {
+				t.Errorf("checkbox in the output:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestStrikethrough(t *testing.T) {
+	inputs := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"alert", "> [!NOTE]\n> a ~~old~~ b\n", "<p>a %sold</del> b</p>"},
+		{"task list", "- [x] ~~done~~\n- [ ] open\n", "checked>  %sdone</del></li>"},
+		{"inline code", "`code` ~~gone~~ `more`\n", "<p><code>code</code> %sgone</del> <code>more</code></p>"},
+	}
+	classes := []struct {
+		name  string
+		class string
+		tag   string
+	}{
+		{"no class", "", "<del>"},
+		{"class", "strike", `<del class="strike">`},
+	}
+	for _, c := range classes {
+		p, err := NewParserWithDefaults(WithStrikethroughClass(c.class))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range inputs {
+			t.Run(tt.name+"/"+c.name, func(t *testing.T) {
+				out, err := p.MdToHTML([]byte(tt.input))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := fmt.Sprintf(tt.want, c.tag); !strings.Contains(string(out), want) {
+					t.Errorf("output lacks %q:\n%s", want, out)
+				}
+			})
+		}
+	}
+}
+
+func TestMathScript(t *testing.T) {
+	includes := map[string]string{
+		"katex":   "katex.min.js",
+		"mathjax": "tex-chtml.js",
+	}
+	for engine, include := range includes {
+		t.Run(engine, func(t *testing.T) {
+			p, err := NewParserWithDefaults(WithMathEngine(engine))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := p.MdToHTML([]byte("Text $a$ and $b$.\n\n$$\nc\n$$\n\nMore $d$.\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			html := string(out)
+			if n := strings.Count(html, include); n != 1 {
+				t.Errorf("want one include of %s, got %d:\n%s", include, n, html)
+			}
+			// The script is written in front of the document, not inside of
+			// its first paragraph
+			if strings.Index(html, include) > strings.Index(html, "<p>") {
+				t.Errorf("include inside of the document:\n%s", html)
+			}
+		})
+	}
+
+	p, err := NewParserWithDefaults(WithMathEngine("katex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := p.MdToHTML([]byte("No math\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "<script") {
+		t.Errorf("include without math:\n%s", out)
+	}
+}
+
+func TestTaskMarkers(t *testing.T) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		marker string
+		want   string
+	}{
+		{"[ ]", `class="task-list-item-checkbox">  task`},
+		{"[x]", `class="task-list-item-checkbox" checked>  task`},
+		{"[X]", `class="task-list-item-checkbox" checked>  task`},
+		{"[✓]", `class="task-list-item-checkbox" checked>  task`},
+		{"[✗]", `class="task-list-item-checkbox">  task`},
+		{"[-]", "<li>[-] task</li>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.marker, func(t *testing.T) {
+			out, err := p.MdToHTML([]byte("- " + tt.marker + " task\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("output lacks %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
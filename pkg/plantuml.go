@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPlantUMLServer  = "https://www.plantuml.com/plantuml/svg/"
+	defaultPlantUMLTimeout = 10 * time.Second
+)
+
+// PlantUMLRenderer renders plantuml and puml code blocks to SVG, either with a
+// PlantUML server or with a local plantuml.jar
+type PlantUMLRenderer struct {
+	// URL of the SVG endpoint of the server, defaults to the public server
+	ServerURL string
+	// Client used for the server, defaults to http.DefaultClient
+	Client *http.Client
+	// Timeout of rendering a diagram, defaults to 10 seconds
+	Timeout time.Duration
+	// Path of plantuml.jar, rendering with java instead of the server
+	JarPath string
+}
+
+func (p PlantUMLRenderer) Languages() []string {
+	return []string{"plantuml", "puml"}
+}
+
+func (p PlantUMLRenderer) Render(content, theme string) (string, error) {
+	if !strings.HasPrefix(strings.TrimSpace(content), "@start") {
+		content = "@startuml\n" + content + "\n@enduml\n"
+	}
+
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = defaultPlantUMLTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var svg []byte
+	var err error
+	if p.JarPath != "" {
+		svg, err = p.renderJar(ctx, content, theme)
+	} else {
+		svg, err = p.renderServer(ctx, content)
+	}
+	if err != nil {
+		return "", fmt.Errorf("plantuml: %w", err)
+	}
+
+	// Drop the XML declaration in front of the svg element
+	if i := bytes.Index(svg, []byte("<svg")); i > 0 {
+		svg = svg[i:]
+	}
+	return fmt.Sprintf(`<div class="plantuml">%s</div>`, svg), nil
+}
+
+func (p PlantUMLRenderer) renderServer(ctx context.Context, content string) ([]byte, error) {
+	encoded, err := encodePlantUML(content)
+	if err != nil {
+		return nil, err
+	}
+
+	server := p.ServerURL
+	if server == "" {
+		server = defaultPlantUMLServer
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(server, "/")+"/"+encoded, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server responded with %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (p PlantUMLRenderer) renderJar(ctx context.Context, content, theme string) ([]byte, error) {
+	args := []string{"-jar", p.JarPath, "-tsvg", "-pipe"}
+	if theme == "dark" {
+		args = append(args, "-darkmode")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "java", args...)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// encodePlantUML encodes a diagram for the URL of a PlantUML server: deflate
+// compressed and encoded with the base64 variant of PlantUML
+func encodePlantUML(content string) (string, error) {
+	var compressed bytes.Buffer
+	zw, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := zw.Write([]byte(content)); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+	data := compressed.Bytes()
+	var encoded strings.Builder
+	for i := 0; i < len(data); i += 3 {
+		// Missing bytes of the last group are encoded as zeros
+		var b [3]byte
+		copy(b[:], data[i:])
+		encoded.WriteByte(alphabet[b[0]>>2])
+		encoded.WriteByte(alphabet[(b[0]&0x3)<<4|b[1]>>4])
+		encoded.WriteByte(alphabet[(b[1]&0xF)<<2|b[2]>>6])
+		encoded.WriteByte(alphabet[b[2]&0x3F])
+	}
+	return encoded.String(), nil
+}
@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Matches @username, but not e-mail addresses
+var mentionRegex = regexp.MustCompile(`(^|[^\w@/.])@([A-Za-z0-9][A-Za-z0-9-]{0,38})\b`)
+
+// Matches #123, but not within words, URLs or entities like &#123;
+var issueRegex = regexp.MustCompile(`(^|[^\w&#/])#(\d+)\b`)
+
+// mentionBaseURL returns the URL usernames are appended to, or an empty
+// string if there is none
+func (m Parser) mentionBaseURL() string {
+	base := m.opts.MentionBaseURL
+	if base == "" && m.opts.RepoURL != "" {
+		repo, err := url.Parse(m.opts.RepoURL)
+		if err != nil || repo.Host == "" {
+			return ""
+		}
+		base = repo.Scheme + "://" + repo.Host
+	}
+	if base != "" && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// Matches ==highlighted== text, which must not start or end with a space
+var markRegex = regexp.MustCompile(`==(\S(?:.*?\S)?)==`)
+
+// Match ^superscript^ and ~subscript~ text, which must not contain spaces like
+// in pandoc, so that e.g. "2^8 and 2^16" is kept. subRegex matches runs of
+// tildes as well, so that ~~ is never taken for a subscript
+var (
+	supRegex = regexp.MustCompile(`\^([^^\s]+)\^`)
+	subRegex = regexp.MustCompile(`~~+|~([^~\s]+)~`)
+)
+
+// textReplacer replaces the matches of regex in text with HTML
+type textReplacer struct {
+	regex   *regexp.Regexp
+	replace func(match []string) string
+}
+
+// replaceText applies the replacers in order, each of them only to the text
+// not replaced by the previous ones. The remaining text is HTML-escaped.
+func replaceText(text string, replacers []textReplacer) string {
+	if len(replacers) == 0 || text == "" {
+		return escapeText(text)
+	}
+	r, rest := replacers[0], replacers[1:]
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.regex.FindAllStringSubmatchIndex(text, -1) {
+		match := make([]string, len(loc)/2)
+		for i := range match {
+			if loc[2*i] >= 0 {
+				match[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(replaceText(text[last:loc[0]], rest))
+		b.WriteString(r.replace(match))
+		last = loc[1]
+	}
+	b.WriteString(replaceText(text[last:], rest))
+	return b.String()
+}
+
+// Matches character references, which the markdown parser keeps in the text
+var entityRegex = regexp.MustCompile(`&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9A-Fa-f]+);`)
+
+// escapeText escapes text like the HTML renderer, so that smart typography
+// still recognizes the quotes. Character references are kept.
+func escapeText(text string) string {
+	var b bytes.Buffer
+	last := 0
+	for _, loc := range entityRegex.FindAllStringIndex(text, -1) {
+		html.EscapeHTML(&b, []byte(text[last:loc[0]]))
+		b.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	html.EscapeHTML(&b, []byte(text[last:]))
+	return b.String()
+}
+
+// textReplacers returns the replacers of renderHookText for the text node
+func (m Parser) textReplacers(node ast.Node) []textReplacer {
+	var replacers []textReplacer
+
+	if m.opts.MarkHighlight {
+		replacers = append(replacers, textReplacer{markRegex, func(match []string) string {
+			// The highlighted text is replaced like the text around it
+			return "<mark>" + replaceText(match[1], replacers[1:]) + "</mark>"
+		}})
+	}
+	if m.opts.SubSuperscript {
+		sup := len(replacers)
+		replacers = append(replacers, textReplacer{supRegex, func(match []string) string {
+			return "<sup>" + replaceText(match[1], replacers[sup+1:]) + "</sup>"
+		}})
+		sub := len(replacers)
+		replacers = append(replacers, textReplacer{subRegex, func(match []string) string {
+			// Runs of tildes, e.g. of unclosed ~~strikethrough~~, are kept
+			if match[1] == "" {
+				return match[0]
+			}
+			return "<sub>" + replaceText(match[1], replacers[sub+1:]) + "</sub>"
+		}})
+	}
+
+	// Wikilinks use the same syntax and take precedence
+	if m.opts.KeyboardShortcuts && m.opts.WikiBase == "" {
+		replacers = append(replacers, textReplacer{wikiLinkRegex, func(match []string) string {
+			return keyboardShortcut(match[0][2 : len(match[0])-2])
+		}})
+	}
+
+	// Links must not be nested
+	if !insideLink(node) {
+		if base := m.opts.WikiBase; base != "" {
+			replacers = append(replacers, textReplacer{wikiLinkRegex, func(match []string) string {
+				return wikiLink(base, match)
+			}})
+		}
+		if base := m.mentionBaseURL(); m.opts.GitHubMentions && base != "" {
+			replacers = append(replacers, textReplacer{mentionRegex, func(match []string) string {
+				return escapeText(match[1]) + `<a href="` + template.HTMLEscapeString(base+match[2]) + `" class="mention">@` + match[2] + "</a>"
+			}})
+		}
+		if repo := strings.TrimSuffix(m.opts.RepoURL, "/"); m.opts.IssueReferences && repo != "" {
+			replacers = append(replacers, textReplacer{issueRegex, func(match []string) string {
+				return escapeText(match[1]) + `<a href="` + template.HTMLEscapeString(repo+"/issues/"+match[2]) + `" class="issue-ref">#` + match[2] + "</a>"
+			}})
+		}
+	}
+
+	if m.opts.EmojiEnabled && !insideCode(node) {
+		replacers = append(replacers, textReplacer{emojiRegex, func(match []string) string {
+			return m.replaceEmoji(match[0])
+		}})
+	}
+	return replacers
+}
+
+func insideLink(node ast.Node) bool {
+	for parent := node.GetParent(); parent != nil; parent = parent.GetParent() {
+		if _, ok := parent.(*ast.Link); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// insideCode reports whether the text is part of code. gomarkdown keeps code
+// as literal of leaves, this guards against parsers adding texts to them.
+func insideCode(node ast.Node) bool {
+	for parent := node.GetParent(); parent != nil; parent = parent.GetParent() {
+		switch parent.(type) {
+		case *ast.Code, *ast.CodeBlock:
+			return true
+		}
+	}
+	return false
+}
+
+// keyboardShortcut renders a shortcut like "Ctrl+C" as nested <kbd> elements
+func keyboardShortcut(shortcut string) string {
+	parts := strings.Split(shortcut, "+")
+	var keys []string
+	for i := 0; i < len(parts); i++ {
+		key := strings.TrimSpace(parts[i])
+		// "Ctrl++" is split into "Ctrl", "" and ""
+		if key == "" && i+1 < len(parts) && strings.TrimSpace(parts[i+1]) == "" {
+			key = "+"
+			i++
+		}
+		keys = append(keys, "<kbd>"+template.HTMLEscapeString(key)+"</kbd>")
+	}
+	if len(keys) == 1 {
+		return keys[0]
+	}
+	return "<kbd>" + strings.Join(keys, "+") + "</kbd>"
+}
@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubSuperscript(t *testing.T) {
+	p, err := NewParserWithDefaults(WithSubSuperscript(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// Sub- and superscripts do not contain spaces
+		{"2^8 and 2^16\n", "<p>2^8 and 2^16</p>"},
+		{"~/a and ~/b\n", "<p>~/a and ~/b</p>"},
+		{"~~strike~~\n", "<p><del>strike</del></p>"},
+		{"H~2~O\n", "<p>H<sub>2</sub>O</p>"},
+		{"x^2^\n", "<p>x<sup>2</sup></p>"},
+	}
+	for _, tt := range tests {
+		out, err := p.MdToHTML([]byte(tt.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(out), tt.want) {
+			t.Errorf("%q: output lacks %q:\n%s", tt.input, tt.want, out)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Matches emoji names, in contrast to emojiRegex which also matches e.g.
+// ":30:" of a time
+var emojiNameRegex = regexp.MustCompile(`^:[a-z_+-][a-z0-9_+-]*:$`)
+
+type ParseResult struct {
+	HTML []byte
+	// Front matter of the document, only decoded with ParseFrontMatter
+	Metadata map[string]any
+	// Problems which did not stop the rendering, e.g. images without alt text
+	Warnings []error
+	Headings []Heading
+}
+
+// Parse renders the document and returns the HTML together with the other
+// results of the rendering
+func (m Parser) Parse(input []byte) ParseResult {
+	var result ParseResult
+
+	if m.opts.ParseFrontMatter {
+		meta, _, err := ParseFrontMatter(input)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err)
+		}
+		result.Metadata = meta
+	}
+
+	doc := m.parse(input)
+	html, err := m.renderDoc(context.Background(), doc)
+	result.HTML = html
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		result.Warnings = append(result.Warnings, joined.Unwrap()...)
+	} else if err != nil {
+		result.Warnings = append(result.Warnings, err)
+	}
+
+	result.Warnings = append(result.Warnings, m.warnings(doc)...)
+	// The heading IDs are made unique by the renderer
+	result.Headings = collectHeadings(doc)
+	return result
+}
+
+// warnings reports problems of the document which do not affect rendering
+func (m Parser) warnings(doc ast.Node) []error {
+	var warnings []error
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Image:
+			if strings.TrimSpace(nodeText(node)) == "" {
+				warnings = append(warnings, fmt.Errorf("image %q has no alt text", node.Destination))
+			}
+		case *ast.Text:
+			if !m.opts.EmojiEnabled {
+				break
+			}
+			for _, name := range emojiRegex.FindAllString(string(node.Literal), -1) {
+				_, extra := m.opts.ExtraEmoji[name]
+				_, builtin := EmojiMap[name]
+				if !extra && !builtin && emojiNameRegex.MatchString(name) {
+					warnings = append(warnings, fmt.Errorf("unknown emoji %s", name))
+				}
+			}
+		}
+		return ast.GoToNext
+	})
+	return warnings
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// sanitizePolicy keeps the elements and attributes of the HTML rendered by
+// go-grip and removes all others, e.g. scripts and event handlers
+var sanitizePolicy = func() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"a", "abbr", "b", "blockquote", "br", "caption", "cite", "code", "dd", "del", "details", "dfn",
+		"div", "dl", "dt", "em", "figcaption", "figure", "footer", "h1", "h2", "h3", "h4", "h5", "h6",
+		"hr", "i", "img", "input", "ins", "kbd", "li", "mark", "ol", "p", "pre", "q", "s", "samp",
+		"section", "small", "span", "strong", "sub", "summary", "sup", "table", "tbody", "td",
+		"tfoot", "th", "thead", "tr", "u", "ul",
+		// Icons of alerts
+		"svg", "path",
+	)
+	// Elements removed together with their content, in addition to scripts,
+	// styles and frames
+	p.SkipElementsContent("template", "textarea", "select", "button", "form", "embed")
+
+	p.AllowAttrs("class", "id", "title", "dir", "lang", "role", "aria-label", "aria-hidden").Globally()
+	p.AllowDataAttributes()
+	p.AllowAttrs("href", "rel", "target", "name").OnElements("a")
+	p.AllowAttrs("src", "alt", "width", "height", "loading").OnElements("img")
+	// Checkboxes of task lists
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("disabled", "checked").OnElements("input")
+	p.AllowAttrs("open").OnElements("details")
+	p.AllowAttrs("start").OnElements("ol")
+	p.AllowAttrs("align", "colspan", "rowspan").OnElements("td", "th")
+	p.AllowAttrs("viewbox", "version", "width", "height").OnElements("svg")
+	p.AllowAttrs("d", "fill-rule").OnElements("path")
+
+	// Links and images are relative or use one of the schemes
+	p.RequireParseableURLs(true)
+	p.AllowRelativeURLs(true)
+	p.AllowURLSchemes("http", "https", "mailto")
+	return p
+}()
+
+// SafeMdToHTML renders the document like MdToHTML with SanitizeHTML enabled
+func (m Parser) SafeMdToHTML(input []byte) ([]byte, error) {
+	m.opts.SanitizeHTML = true
+	return m.MdToHTMLCtx(context.Background(), input)
+}
+
+// sanitizeHTML removes all elements and attributes from the HTML which are
+// not allowed, e.g. scripts and event handlers
+func sanitizeHTML(input []byte) []byte {
+	return sanitizePolicy.SanitizeBytes(input)
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	p, err := NewParserWithDefaults(WithRawHTMLEnabled(true), WithCodeCopyButton(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		// Substrings which must not be part of the output, in lower case
+		banned []string
+	}{
+		{"script", "<script>alert(1)</script>\n", []string{"<script", "alert(1)"}},
+		{"javascript link", "[x](javascript:alert(1))\n", []string{"javascript:"}},
+		{"javascript raw link", `<a href="JaVaScRiPt:alert(1)">x</a>` + "\n", []string{"javascript:"}},
+		{"javascript with entity", `<a href="java&#x09;script:alert(1)">x</a>` + "\n", []string{"script:"}},
+		{"data url", `<a href="data:text/html,<script>alert(1)</script>">x</a>` + "\n", []string{"data:", "<script"}},
+		{"event handler", `<img src="x.png" onerror="alert(1)">` + "\n", []string{"onerror"}},
+		{"event handler on div", `<div onclick="alert(1)">x</div>` + "\n", []string{"onclick"}},
+		{"svg onload", `<svg onload="alert(1)"><path d="M0"/></svg>` + "\n", []string{"onload"}},
+		{"svg script", `<svg><script>alert(1)</script></svg>` + "\n", []string{"<script", "alert(1)"}},
+		{"math", `<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>` + "\n", []string{"<math", "onerror"}},
+		{"malformed tag", "<scr<script>ipt>alert(1)</script>\n", []string{"<script"}},
+		{"unclosed tag", `<img src=x onerror=alert(1)//` + "\n", []string{"onerror"}},
+		{"iframe", `<iframe src="https://example.com"></iframe>` + "\n", []string{"<iframe"}},
+		{"style attribute", `<p style="background:url(javascript:alert(1))">x</p>` + "\n", []string{"style=", "javascript:"}},
+		{"form", `<form action="https://example.com"><button>x</button></form>` + "\n", []string{"<form", "<button"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := p.SafeMdToHTML([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lower := strings.ToLower(string(out))
+			for _, banned := range tt.banned {
+				if strings.Contains(lower, banned) {
+					t.Errorf("output contains %q:\n%s", banned, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeHTMLKeepsMarkup(t *testing.T) {
+	p, err := NewParserWithDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := p.SafeMdToHTML([]byte("> [!NOTE]\n> text\n\n- [x] done\n\n[link](https://example.com)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`class="markdown-alert markdown-alert-note"`,
+		"<svg", "<path",
+		`<input type="checkbox" disabled="" class="task-list-item-checkbox" checked="">`,
+		`href="https://example.com">link</a>`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output lacks %s:\n%s", want, out)
+		}
+	}
+}
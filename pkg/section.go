@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+type Section struct {
+	Heading Heading
+	HTML    []byte
+}
+
+type sectionPart struct {
+	heading *ast.Heading
+	doc     *ast.Document
+}
+
+// SplitBySections renders the document split at every second level heading.
+// The content in front of the first one is returned as title section, with
+// the first level heading if there is one.
+func (m Parser) SplitBySections(input []byte) ([]Section, error) {
+	doc := m.parse(input)
+	// The IDs are assigned upfront, so they stay unique across sections
+	m.assignHeadingIDs(doc)
+
+	var parts []*sectionPart
+	for _, child := range doc.GetChildren() {
+		heading, isHeading := child.(*ast.Heading)
+		switch {
+		case isHeading && heading.Level == 2:
+			parts = append(parts, &sectionPart{heading: heading, doc: &ast.Document{}})
+		case len(parts) == 0:
+			parts = append(parts, &sectionPart{doc: &ast.Document{}})
+		}
+
+		last := parts[len(parts)-1]
+		if isHeading && heading.Level == 1 && last.heading == nil {
+			last.heading = heading
+		}
+		// ast.AppendChild would drop the children of the moved node
+		child.SetParent(last.doc)
+		last.doc.Children = append(last.doc.Children, child)
+	}
+
+	var errs []error
+	sections := make([]Section, len(parts))
+	for i, part := range parts {
+		html, err := m.renderDoc(context.Background(), part.doc)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		sections[i] = Section{HTML: html}
+		// The heading ID is final after rendering
+		if part.heading != nil {
+			sections[i].Heading = newHeading(part.heading)
+		}
+	}
+	return sections, errors.Join(errs...)
+}
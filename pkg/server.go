@@ -9,12 +9,9 @@ import (
 	"net/url"
 	"path"
 	"regexp"
-	"strings"
 	"text/template"
 
 	"github.com/aarol/reload"
-	chroma_html "github.com/alecthomas/chroma/v2/formatters/html"
-	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/chrishrb/go-grip/defaults"
 )
 
@@ -74,15 +71,28 @@ func (s *Server) Serve(file string) error {
 				log.Fatal(err)
 				return
 			}
-			htmlContent := s.parser.MdToHTML(bytes)
+			htmlContent, err := s.parser.MdToHTMLCtx(r.Context(), bytes)
+			if err != nil {
+				log.Println("Error:", err)
+			}
+
+			lightTheme, darkTheme := s.parser.chromaThemes()
+			cssCodeLight, err := s.parser.ExtractCSS(lightTheme)
+			if err != nil {
+				log.Println("Error:", err)
+			}
+			cssCodeDark, err := s.parser.ExtractCSS(darkTheme)
+			if err != nil {
+				log.Println("Error:", err)
+			}
 
 			// Serve
 			err = serveTemplate(w, htmlStruct{
 				Content:      string(htmlContent),
 				Theme:        s.theme,
 				BoundingBox:  s.boundingBox,
-				CssCodeLight: getCssCode("github"),
-				CssCodeDark:  getCssCode("github-dark"),
+				CssCodeLight: cssCodeLight,
+				CssCodeDark:  cssCodeDark,
 			})
 			if err != nil {
 				log.Fatal(err)
@@ -153,11 +163,3 @@ func serveTemplate(w http.ResponseWriter, html htmlStruct) error {
 	err = tmpl.Execute(w, html)
 	return err
 }
-
-func getCssCode(style string) string {
-	buf := new(strings.Builder)
-	formatter := chroma_html.New(chroma_html.WithClasses(true))
-	s := styles.Get(style)
-	_ = formatter.WriteCSS(buf, s)
-	return buf.String()
-}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+const defaultWordsPerMinute = 200
+
+// WordCount returns the number of words of the document text
+func (m Parser) WordCount(input []byte) int {
+	count := 0
+	ast.WalkFunc(m.parse(input), func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Text, *abbreviation:
+			count += len(strings.Fields(string(node.AsLeaf().Literal)))
+		case *ast.Code, *ast.CodeBlock:
+			if m.opts.CountCodeWords {
+				count += len(strings.Fields(string(node.AsLeaf().Literal)))
+			}
+		}
+		return ast.GoToNext
+	})
+	return count
+}
+
+// ReadingTime estimates the time to read the document based on
+// WordsPerMinute
+func (m Parser) ReadingTime(input []byte) time.Duration {
+	wpm := m.opts.WordsPerMinute
+	if wpm <= 0 {
+		wpm = defaultWordsPerMinute
+	}
+	return time.Duration(m.WordCount(input)) * time.Minute / time.Duration(wpm)
+}
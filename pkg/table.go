@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"html/template"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// Matches the paragraph in front of a table naming its caption
+var tableCaptionRegex = regexp.MustCompile(`^Table:\s*(.+)$`)
+
+func (m Parser) renderHookTable(w io.Writer, table *ast.Table, entering bool) (ast.WalkStatus, bool, error) {
+	caption, hasCaption := m.tableCaption(table)
+	if !m.opts.ResponsiveTables && !hasCaption {
+		return ast.GoToNext, false, nil
+	}
+
+	var s string
+	if entering {
+		// Wide tables scroll instead of overflowing on small screens
+		if m.opts.ResponsiveTables {
+			s = `<div class="table-responsive" style="overflow-x:auto;">` + "\n"
+		}
+		s += html.TagWithAttributes("<table", html.BlockAttrs(table))
+		if hasCaption {
+			s += "\n<caption>" + template.HTMLEscapeString(caption) + "</caption>"
+		}
+	} else {
+		s = "</table>\n"
+		if m.opts.ResponsiveTables {
+			s += "</div>\n"
+		}
+	}
+	_, err := io.WriteString(w, s)
+	return ast.GoToNext, true, err
+}
+
+// tableCaption returns the caption of a table given by a "Table: caption"
+// paragraph in front of it
+func (m Parser) tableCaption(table *ast.Table) (string, bool) {
+	if !m.opts.TableCaptions {
+		return "", false
+	}
+	paragraph, ok := ast.GetPrevNode(table).(*ast.Paragraph)
+	if !ok {
+		return "", false
+	}
+	match := tableCaptionRegex.FindStringSubmatch(strings.TrimSpace(nodeText(paragraph)))
+	if match == nil {
+		return "", false
+	}
+	// Left out HTML can leave double spaces
+	return strings.Join(strings.Fields(match[1]), " "), true
+}
+
+// isTableCaption reports whether the paragraph is the caption of the
+// following table, which is rendered as part of the table
+func (m Parser) isTableCaption(paragraph *ast.Paragraph) bool {
+	table, ok := ast.GetNextNode(paragraph).(*ast.Table)
+	if !ok {
+		return false
+	}
+	_, ok = m.tableCaption(table)
+	return ok
+}
+
+// renderHookTableRow adds alternating classes to the rows of the table body,
+// starting with row-odd
+func (m Parser) renderHookTableRow(w io.Writer, row *ast.TableRow, entering bool) (ast.WalkStatus, bool, error) {
+	body, ok := row.GetParent().(*ast.TableBody)
+	if !m.opts.StripedTables || !ok {
+		return ast.GoToNext, false, nil
+	}
+
+	// Line breaks like the ones of the renderer
+	s := "</tr>\n"
+	if entering {
+		class := "row-odd"
+		if slices.Index(body.GetChildren(), ast.Node(row))%2 == 1 {
+			class = "row-even"
+		}
+		s = "\n" + `<tr class="` + class + `">`
+	}
+	_, err := io.WriteString(w, s)
+	return ast.GoToNext, true, err
+}
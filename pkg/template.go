@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"errors"
+	"html/template"
+	"io/fs"
+	"strings"
+
+	"github.com/chrishrb/go-grip/defaults"
+)
+
+// parseTemplate parses a template from the TemplateFS of the parser and falls
+// back to the built-in templates if it does not contain the file. Parsed
+// templates are cached for the lifetime of the parser.
+func (m Parser) parseTemplate(name string) (*template.Template, error) {
+	if m.templates == nil {
+		return m.parseTemplateFS(name)
+	}
+
+	if tmpl, ok := m.templates.Load(name); ok {
+		return tmpl.(*template.Template), nil
+	}
+
+	tmpl, err := m.parseTemplateFS(name)
+	if err != nil {
+		return nil, err
+	}
+	m.templates.Store(name, tmpl)
+	return tmpl, nil
+}
+
+func (m Parser) parseTemplateFS(name string) (*template.Template, error) {
+	if m.opts.TemplateFS != nil {
+		if _, err := fs.Stat(m.opts.TemplateFS, name); err == nil {
+			return template.ParseFS(m.opts.TemplateFS, name)
+		}
+	}
+	return template.ParseFS(defaults.Templates, name)
+}
+
+func (m Parser) templateExists(name string) bool {
+	if m.opts.TemplateFS != nil {
+		if _, err := fs.Stat(m.opts.TemplateFS, name); err == nil {
+			return true
+		}
+	}
+	_, err := fs.Stat(defaults.Templates, name)
+	return err == nil
+}
+
+// loadTemplates parses all built-in templates and the ones of the TemplateFS
+// of the parser, so that broken templates are reported on construction
+func (m Parser) loadTemplates() error {
+	names := map[string]bool{}
+	collect := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".html") {
+			names[path] = true
+		}
+		return nil
+	}
+
+	if err := fs.WalkDir(defaults.Templates, "templates", collect); err != nil {
+		return err
+	}
+	if m.opts.TemplateFS != nil {
+		if err := fs.WalkDir(m.opts.TemplateFS, ".", collect); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for name := range names {
+		if _, err := m.parseTemplate(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+)
+
+// GenerateTOC renders the document together with a nested list of links to
+// its headings
+func (m Parser) GenerateTOC(input []byte) ([]byte, []byte, error) {
+	doc := m.parse(input)
+
+	// The renderer makes the heading IDs unique, so the headings have to be
+	// collected afterwards
+	body, err := m.renderDoc(context.Background(), doc)
+
+	var headings []Heading
+	for _, h := range collectHeadings(doc) {
+		if m.opts.TOCMaxDepth == 0 || h.Level <= m.opts.TOCMaxDepth {
+			headings = append(headings, h)
+		}
+	}
+
+	return renderTOC(headings), body, err
+}
+
+func renderTOC(headings []Heading) []byte {
+	var buf bytes.Buffer
+	var levels []int
+
+	for _, h := range headings {
+		if len(levels) == 0 || h.Level > levels[len(levels)-1] {
+			buf.WriteString("<ul>")
+			levels = append(levels, h.Level)
+		} else {
+			buf.WriteString("</li>")
+			for len(levels) > 1 && h.Level <= levels[len(levels)-2] {
+				buf.WriteString("</ul></li>")
+				levels = levels[:len(levels)-1]
+			}
+			levels[len(levels)-1] = h.Level
+		}
+		fmt.Fprintf(&buf, `<li><a href="#%s">%s</a>`, template.HTMLEscapeString(h.ID), template.HTMLEscapeString(h.Text))
+	}
+
+	for range levels {
+		buf.WriteString("</li></ul>")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Matches opening, closing and self-closing HTML tags
+var htmlTagRegex = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)(?:\s[^<>]*?)?(/?)>`)
+
+// Elements without a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// Warning is a quality problem of a document found by Validate
+type Warning struct {
+	// Kind of the problem [missing-alt/heading-skip/duplicate-id/unclosed-html]
+	Kind string
+	// Line of the problem in the input, starting at 1, or 0 if it could not be
+	// determined
+	Line    int
+	Message string
+}
+
+// Validate checks the document for problems like images without alt text,
+// without rendering it
+func (m Parser) Validate(input []byte) []Warning {
+	// The image destinations are searched in the input, so they must not be
+	// resolved
+	m.opts.BaseURL = nil
+
+	lines := strings.Split(string(bytes.ReplaceAll(input, []byte("\r\n"), []byte("\n"))), "\n")
+	// Nodes appear in source order, so the search continues after the previous
+	// one
+	cursor := 0
+	find := func(match func(i int) bool) int {
+		for i := cursor; i < len(lines); i++ {
+			if match(i) {
+				cursor = i
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	var warnings []Warning
+	ids := map[string]bool{}
+	level := 0
+	ast.WalkFunc(m.parse(input), func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+
+		switch node := node.(type) {
+		case *ast.CodeBlock:
+			// Skip the code, it may look like anything
+			if line := findCodeBlockLine(lines, cursor, node); line > 0 {
+				cursor = line - 1 + strings.Count(string(node.Literal), "\n")
+			}
+		case *ast.Heading:
+			text := nodeText(node)
+			line := find(func(i int) bool {
+				if !strings.Contains(lines[i], text) {
+					return false
+				}
+				setext := i+1 < len(lines) && strings.Trim(lines[i+1], "=- \t") == "" && strings.TrimSpace(lines[i+1]) != ""
+				return strings.HasPrefix(strings.TrimLeft(lines[i], " \t>"), "#") || setext
+			})
+
+			if level > 0 && node.Level > level+1 {
+				warnings = append(warnings, Warning{"heading-skip", line, fmt.Sprintf("heading %q of level %d follows level %d", text, node.Level, level)})
+			}
+			level = node.Level
+
+			if ids[node.HeadingID] {
+				warnings = append(warnings, Warning{"duplicate-id", line, fmt.Sprintf("heading %q has the duplicate ID %q", text, node.HeadingID)})
+			}
+			ids[node.HeadingID] = true
+		case *ast.Image:
+			line := find(func(i int) bool {
+				return strings.Contains(lines[i], "]("+string(node.Destination))
+			})
+			if strings.TrimSpace(nodeText(node)) == "" {
+				warnings = append(warnings, Warning{"missing-alt", line, fmt.Sprintf("image %q has no alt text", node.Destination)})
+			}
+		case *ast.HTMLBlock:
+			first, _, _ := strings.Cut(string(node.Literal), "\n")
+			line := find(func(i int) bool {
+				return strings.TrimSpace(lines[i]) == strings.TrimSpace(first)
+			})
+			for _, tag := range unclosedTags(string(node.Literal)) {
+				warnings = append(warnings, Warning{"unclosed-html", line, fmt.Sprintf("HTML element <%s> is not closed", tag)})
+			}
+		}
+		return ast.GoToNext
+	})
+	return warnings
+}
+
+// Elements whose closing tag may be omitted
+var optionalEndTags = map[string]bool{
+	"p": true, "li": true, "dt": true, "dd": true, "tr": true, "td": true, "th": true,
+	"thead": true, "tbody": true, "tfoot": true, "option": true,
+}
+
+// unclosedTags returns the elements opened but not closed in the HTML
+func unclosedTags(html string) []string {
+	var open, unclosed []string
+	for _, match := range htmlTagRegex.FindAllStringSubmatch(html, -1) {
+		name := strings.ToLower(match[2])
+		switch {
+		case voidElements[name] || match[3] == "/":
+		case match[1] == "":
+			open = append(open, name)
+		default:
+			// A closing tag also ends the elements opened inside of it
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == name {
+					unclosed = append(unclosed, open[i+1:]...)
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+	unclosed = append(unclosed, open...)
+	return slices.DeleteFunc(unclosed, func(name string) bool {
+		return optionalEndTags[name]
+	})
+}
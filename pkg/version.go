@@ -0,0 +1,31 @@
+package pkg
+
+import "runtime/debug"
+
+const modulePath = "github.com/chrishrb/go-grip"
+
+// Version returns the version of the go-grip module in the build, e.g.
+// "v0.5.0", or "(devel)" if it is unknown
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+
+	var module *debug.Module
+	if info.Main.Path == modulePath {
+		module = &info.Main
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			module = dep
+		}
+	}
+	if module != nil && module.Replace != nil {
+		module = module.Replace
+	}
+	if module == nil || module.Version == "" {
+		return "(devel)"
+	}
+	return module.Version
+}
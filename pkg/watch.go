@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	wg      sync.WaitGroup
+}
+
+func (f *fileWatcher) Close() error {
+	err := f.watcher.Close()
+	f.wg.Wait()
+	return err
+}
+
+// WatchAndRender renders the markdown file as HTML page to out, initially
+// and whenever it changes, until the returned watcher is closed. onChange is
+// called after every rendering, if it failed with the error, which is also
+// shown on the page.
+func (m Parser) WatchAndRender(path string, out string, onChange func(err error)) (io.Closer, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// Editors often replace the file, so the directory is watched
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	render := func() {
+		err := m.renderFile(path, out)
+		if onChange != nil {
+			onChange(err)
+		}
+	}
+	render()
+
+	f := &fileWatcher{watcher: watcher}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == filepath.Clean(path) && event.Has(fsnotify.Write|fsnotify.Create) {
+					render()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if onChange != nil {
+					onChange(err)
+				}
+			}
+		}
+	}()
+	return f, nil
+}
+
+// renderFile renders the markdown file as HTML page to out. If rendering
+// fails, an error page is written instead.
+func (m Parser) renderFile(path, out string) error {
+	opts := PageOptions{
+		Title:           filepath.Base(path),
+		BodyClass:       "markdown-body",
+		InlineChromaCSS: true,
+	}
+
+	input, err := os.ReadFile(path)
+	var page []byte
+	if err == nil {
+		page, err = m.MdToHTMLPage(input, opts)
+	}
+	if page == nil {
+		page = errorPage(opts.Title, err)
+	}
+
+	if writeErr := os.WriteFile(out, page, 0o644); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func errorPage(title string, err error) []byte {
+	return []byte(fmt.Sprintf("<!doctype html>\n<html>\n<head><meta charset=\"utf-8\" /><title>%s</title></head>\n<body><pre class=\"error\">%s</pre></body>\n</html>\n",
+		template.HTMLEscapeString(title), template.HTMLEscapeString(err.Error())))
+}
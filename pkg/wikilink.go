@@ -0,0 +1,21 @@
+package pkg
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// Matches [[Page Name]] and [[Page Name|Alias]]
+var wikiLinkRegex = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+
+// wikiLink renders a wikilink match as link to the page below base
+func wikiLink(base string, match []string) string {
+	page := strings.TrimSpace(match[1])
+	text := page
+	if alias := strings.TrimSpace(match[2]); alias != "" {
+		text = alias
+	}
+	href := strings.TrimSuffix(base, "/") + "/" + slugify(page)
+	return `<a href="` + template.HTMLEscapeString(href) + `" class="wikilink">` + template.HTMLEscapeString(text) + "</a>"
+}